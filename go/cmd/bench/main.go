@@ -0,0 +1,193 @@
+// Command bench runs the scheduler on exam timetabling benchmarks and reports penalty and timing,
+// to track solver quality across releases.
+//
+// Usage:
+//
+//	go run ./cmd/bench [flags] instance...
+//
+// An instance is either an ITC2007 examination file (.exam) or the path of a Carter/Toronto
+// instance without its extension, such as data/car-s-91 for car-s-91.crs and car-s-91.stu.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"exam-scheduler/pkg/scheduler"
+)
+
+// torontoPeriods is the number of periods each Toronto instance is usually solved with.
+var torontoPeriods = map[string]int{
+	"car-f-92": 32,
+	"car-s-91": 35,
+	"ear-f-83": 24,
+	"hec-s-92": 18,
+	"kfu-s-93": 20,
+	"lse-f-91": 18,
+	"pur-s-93": 42,
+	"rye-s-93": 23,
+	"sta-f-83": 13,
+	"tre-s-92": 23,
+	"uta-s-92": 35,
+	"ute-s-92": 10,
+	"yor-f-83": 21,
+}
+
+// Result is the outcome of one benchmark run.
+type Result struct {
+	Instance         string  `json:"instance"`
+	Seed             int64   `json:"seed"` // Same seed, same result, so releases can be compared
+	Courses          int     `json:"courses"`
+	Students         int     `json:"students"`
+	Slots            int     `json:"slots"`
+	Penalty          float64 `json:"penalty"`
+	CarterCost       float64 `json:"carterCost"`
+	SlotsUsed        int     `json:"slotsUsed"`
+	Conflicts        int     `json:"conflicts"`
+	CapacityWarnings int     `json:"capacityWarnings"`
+	FailedAttempts   int     `json:"failedAttempts"`
+	Seconds          float64 `json:"seconds"`
+	Error            string  `json:"error,omitempty"`
+}
+
+func main() {
+	periods := flag.Int("periods", 0, "periods for Toronto instances; 0 uses the usual number for known instances")
+	slotsPerDay := flag.Int("slots-per-day", 3, "periods per day for Toronto instances")
+	tries := flag.Int("tries", 10, "scheduling attempts per instance")
+	seed := flag.Int64("seed", 1, "random seed; 0 picks a new one on every run")
+	minGap := flag.Int("min-gap", 0, "minimum gap between a student's exams in minutes")
+	asJSON := flag.Bool("json", false, "print results as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] instance...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var results []Result
+	failed := false
+	for _, path := range flag.Args() {
+		result := run(path, *periods, *slotsPerDay, *tries, *seed, *minGap)
+		if result.Error != "" {
+			failed = true
+		}
+		results = append(results, result)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "instance\tcourses\tstudents\tslots\tused\tpenalty\tcarter\tconflicts\tcapacity\tfailed\tseconds\t")
+		for _, r := range results {
+			if r.Error != "" {
+				fmt.Fprintf(w, "%s\terror: %s\n", r.Instance, r.Error)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%.0f\t%.2f\t%d\t%d\t%d\t%.2f\t\n",
+				r.Instance, r.Courses, r.Students, r.Slots, r.SlotsUsed, r.Penalty, r.CarterCost, r.Conflicts, r.CapacityWarnings, r.FailedAttempts, r.Seconds)
+		}
+		w.Flush()
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// run loads one instance, schedules it and checks the result.
+func run(path string, periods, slotsPerDay, tries int, seed int64, minGap int) Result {
+	name := strings.TrimSuffix(filepath.Base(path), ".exam")
+	result := Result{Instance: name}
+
+	instance, err := load(path, periods, slotsPerDay)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	students := make(map[scheduler.StudentID]bool)
+	for _, reg := range instance.Registrations {
+		students[reg.StudentID] = true
+	}
+	result.Courses, result.Students, result.Slots = len(instance.Courses), len(students), len(instance.Slots)
+
+	config := scheduler.PenaltyConfig{StudentProximityWeight: 1.0, MinGapViolationWeight: 10.0, CampusTravelWeight: 10.0}
+	start := time.Now()
+	graph := scheduler.NewConflictGraph(instance.Courses)
+	schedule, err := scheduler.RunSchedulingAttemptsWithConstraints(tries, seed, instance.Courses, instance.Halls, instance.Slots, instance.AllowedSlots, graph, minGap, config, instance.Constraints)
+	result.Seconds = time.Since(start).Seconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Seed = schedule.Seed
+	result.Penalty = schedule.Penalty
+	result.FailedAttempts = schedule.FailedAttempts
+	result.CarterCost = scheduler.CarterCost(schedule.Assignments, instance.Courses, instance.Slots)
+	used := make(map[scheduler.SlotID]bool)
+	for _, a := range schedule.Assignments {
+		used[a.SlotID] = true
+	}
+	result.SlotsUsed = len(used)
+	if len(instance.Halls) > 0 {
+		result.CapacityWarnings = len(schedule.Report.CapacityWarnings)
+	}
+
+	scheduleCSV, err := scheduler.SerializeAssignments(schedule.Assignments)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	report, err := scheduler.VerifyScheduleWithConstraints(instance.Registrations, scheduleCSV, nil, instance.Slots, instance.Constraints)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Conflicts = report.Conflicts
+	return result
+}
+
+// load reads an ITC2007 file or a Toronto instance, depending on the path.
+func load(path string, periods, slotsPerDay int) (*scheduler.BenchmarkInstance, error) {
+	if strings.HasSuffix(path, ".exam") {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return scheduler.ReadITC2007(f)
+	}
+
+	base := strings.TrimSuffix(strings.TrimSuffix(path, ".crs"), ".stu")
+	if periods == 0 {
+		periods = torontoPeriods[filepath.Base(base)]
+		if periods == 0 {
+			return nil, fmt.Errorf("unknown Toronto instance %s; set -periods", filepath.Base(base))
+		}
+	}
+	crs, err := os.Open(base + ".crs")
+	if err != nil {
+		return nil, err
+	}
+	defer crs.Close()
+	stu, err := os.Open(base + ".stu")
+	if err != nil {
+		return nil, err
+	}
+	defer stu.Close()
+	return scheduler.ReadToronto(crs, stu, periods, slotsPerDay)
+}
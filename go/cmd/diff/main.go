@@ -0,0 +1,108 @@
+// Command diff compares two versions of a schedule and lists the courses that were added,
+// removed, moved to another slot or given other halls, as the web app's diff does.
+//
+// Usage:
+//
+//	go run ./cmd/diff [flags] before.csv after.csv
+//
+// The schedules are CSV files as written by the scheduler. With -registrations, every change
+// also lists the students it affects; -previous-registrations adds the students of the
+// registrations the older schedule was made for, so removed courses count theirs too. Like diff(1), it exits with 0 if the schedules are the
+// same, 1 if they differ and 2 on errors.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"exam-scheduler/pkg/scheduler"
+)
+
+func main() {
+	registrations := flag.String("registrations", "", "registrations CSV, to report the students affected by each change")
+	previousRegistrations := flag.String("previous-registrations", "", "registrations CSV of the older schedule, to count the students of removed and moved courses")
+	asJSON := flag.Bool("json", false, "print the diff as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] before.csv after.csv\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	diff, err := run(flag.Arg(0), flag.Arg(1), *previousRegistrations, *registrations)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "diff: %v\n", err)
+		os.Exit(2)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(diff)
+	} else {
+		fmt.Print(scheduler.FormatDiff(diff))
+	}
+	if len(diff.Changes) > 0 {
+		os.Exit(1)
+	}
+}
+
+// run reads both schedules and the optional registrations and compares the schedules.
+func run(beforePath, afterPath, previousPath, registrationsPath string) (*scheduler.ScheduleDiff, error) {
+	before, err := readSchedule(beforePath)
+	if err != nil {
+		return nil, err
+	}
+	after, err := readSchedule(afterPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without registrations no affected students are reported.
+	courses := make(map[scheduler.CourseID]*scheduler.Course)
+	if registrationsPath != "" {
+		if courses, err = readCourses(registrationsPath); err != nil {
+			return nil, err
+		}
+	}
+	var previous map[scheduler.CourseID]*scheduler.Course
+	if previousPath != "" {
+		if previous, err = readCourses(previousPath); err != nil {
+			return nil, err
+		}
+	}
+	return scheduler.DiffSchedulesWithPrevious(before, after, previous, courses), nil
+}
+
+// readCourses reads the courses of a registrations CSV file.
+func readCourses(path string) (map[scheduler.CourseID]*scheduler.Course, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	courses, _, _, err := scheduler.ReadRegistrations(f, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return courses, nil
+}
+
+// readSchedule reads a schedule CSV file.
+func readSchedule(path string) ([]*scheduler.Assignment, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	assignments, err := scheduler.ReadSchedule(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return assignments, nil
+}
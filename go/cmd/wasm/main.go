@@ -3,11 +3,18 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash"
+	"io"
 	"runtime"
+	"strings"
 	"syscall/js"
 	"time"
+	_ "time/tzdata" // The browser has no zoneinfo files to load timezones from
 
 	"exam-scheduler/pkg/scheduler"
 )
@@ -25,8 +32,34 @@ type RunParams struct {
 	Seed            int64                    `json:"seed"`
 	MinGap          int                      `json:"minGap"`
 	AllowedSlotsCSV string                   `json:"allowedSlotsCSV"`
-	Timezone        string                   `json:"timezone"` // IANA TZ string
+	SlotsCSV        string                   `json:"slotsCSV"`  // Explicit slots; replaces the generated ones
+	Timezone        string                   `json:"timezone"`  // IANA TZ string
+	Objective       string                   `json:"objective"` // "penalty" (default) or "minDays"
+	StrictInput     bool                     `json:"strictInput"`
 	ColumnMapping   *scheduler.ColumnMapping `json:"columnMapping,omitempty"`
+
+	// Registrations and halls may be spreadsheets, passed base64-encoded
+	RegistrationsFormat string `json:"registrationsFormat"` // "csv" (default), "xlsx" or "ods"
+	RegistrationsSheet  string `json:"registrationsSheet"`  // Sheet name; the first sheet if empty
+	HallsFormat         string `json:"hallsFormat"`
+	HallsSheet          string `json:"hallsSheet"`
+
+	// Optional constraint inputs
+	StudentConstraintsCSV string `json:"studentConstraintsCSV"`
+	CourseRelationsCSV    string `json:"courseRelationsCSV"`
+	ForbiddenSlotsCSV     string `json:"forbiddenSlotsCSV"`
+	CourseRequirementsCSV string `json:"courseRequirementsCSV"`
+	HallAvailabilityCSV   string `json:"hallAvailabilityCSV"`
+	TravelTimesCSV        string `json:"travelTimesCSV"`
+	CourseAliasesCSV      string `json:"courseAliasesCSV"`
+	CourseSittingsCSV     string `json:"courseSittingsCSV"`
+	SittingsCSV           string `json:"sittingsCSV"` // Sittings of a published schedule, as returned with it
+
+	StudentProximityWeight *float64 `json:"studentProximityWeight"` // Penalty weight of exams close together (default 1, 0 disables)
+	MinGapViolationWeight  *float64 `json:"minGapViolationWeight"`  // Penalty weight of exams closer than minGap (default 10, 0 disables)
+	CampusTravelWeight     *float64 `json:"campusTravelWeight"`     // Penalty per student who cannot reach the next campus in time (default 10, 0 disables)
+
+	ReportTitle string `json:"reportTitle"` // Heading of the HTML report
 }
 
 type SuccessResponse struct {
@@ -34,13 +67,19 @@ type SuccessResponse struct {
 	ScheduleCSV string                      `json:"scheduleCSV,omitempty"`
 	Report      *scheduler.ValidationReport `json:"report,omitempty"`
 	Stats       *Stats                      `json:"stats,omitempty"`
+	Changes     []scheduler.CourseChange    `json:"changes,omitempty"`
+	// ScheduleJSON is the schedule with its slots, halls, seats, penalty breakdown, seed and input checksums
+	ScheduleJSON string                 `json:"scheduleJSON,omitempty"`
+	SittingsCSV  string                 `json:"sittingsCSV,omitempty"` // Sitting of each student of a split course
+	Diagnostics  []scheduler.Diagnostic `json:"diagnostics,omitempty"` // Skipped or suspicious input rows
 }
 
 type ErrorResponse struct {
-	Success bool                        `json:"success"`
-	Error   string                      `json:"error"`
-	Report  *scheduler.ValidationReport `json:"report,omitempty"`
-	Stats   *Stats                      `json:"stats,omitempty"`
+	Success     bool                        `json:"success"`
+	Error       string                      `json:"error"`
+	Report      *scheduler.ValidationReport `json:"report,omitempty"`
+	Stats       *Stats                      `json:"stats,omitempty"`
+	Diagnostics []scheduler.Diagnostic      `json:"diagnostics,omitempty"`
 }
 
 type Stats struct {
@@ -49,6 +88,38 @@ type Stats struct {
 	Attempts    int     `json:"attempts"`
 	BestPenalty float64 `json:"bestPenalty"`
 	SlotsUsed   int     `json:"slotsUsed"`
+	DaysUsed    int     `json:"daysUsed"`
+	WindowDays  int     `json:"windowDays"` // Exam days from the start date up to the last used day
+	WindowEnd   string  `json:"windowEnd,omitempty"`
+}
+
+type ExportResponse struct {
+	Success bool   `json:"success"`
+	XLSX    string `json:"xlsx"` // Base64-encoded workbook
+}
+
+type ReportResponse struct {
+	Success bool   `json:"success"`
+	HTML    string `json:"html"` // Self-contained HTML document
+}
+
+type ScenarioResponse struct {
+	Success  bool   `json:"success"`
+	Scenario string `json:"scenario"` // Scenario JSON
+}
+
+type DiffResponse struct {
+	Success bool                    `json:"success"`
+	Diff    *scheduler.ScheduleDiff `json:"diff"`
+	Text    string                  `json:"text"`
+}
+
+type RosterResponse struct {
+	Success     bool                    `json:"success"`
+	RosterCSV   string                  `json:"rosterCSV"`
+	Roster      []*scheduler.Duty       `json:"roster"`
+	Report      *scheduler.RosterReport `json:"report"`
+	Diagnostics []scheduler.Diagnostic  `json:"diagnostics,omitempty"` // Skipped or suspicious input rows
 }
 
 type VersionInfo struct {
@@ -62,6 +133,13 @@ func main() {
 	js.Global().Set("version", js.FuncOf(version))
 	js.Global().Set("runSchedule", js.FuncOf(runSchedule))
 	js.Global().Set("verify", js.FuncOf(verify))
+	js.Global().Set("repairSchedule", js.FuncOf(repairSchedule))
+	js.Global().Set("diffSchedules", js.FuncOf(diffSchedules))
+	js.Global().Set("assignInvigilators", js.FuncOf(assignInvigilators))
+	js.Global().Set("exportScheduleXLSX", js.FuncOf(exportScheduleXLSX))
+	js.Global().Set("exportScheduleHTML", js.FuncOf(exportScheduleHTML))
+	js.Global().Set("saveScenario", js.FuncOf(saveScenario))
+	js.Global().Set("runScenario", js.FuncOf(runScenario))
 	<-c
 }
 
@@ -81,15 +159,32 @@ func version(this js.Value, args []js.Value) interface{} {
 func runSchedule(this js.Value, args []js.Value) interface{} {
 	startTime := time.Now()
 
-	regCSV := args[0].String()
-	hallsCSV := args[1].String()
+	// Registrations and halls are read straight from the JS values, see inputReader
+	regInput := args[0]
+	hallsInput := args[1]
 	paramsJSON := args[2].String()
 
 	var params RunParams
 	if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
 		return marshalError(fmt.Sprintf("failed to parse params JSON: %v", err), nil, 0, 0)
 	}
+	return schedule(regInput, hallsInput, &params, startTime)
+}
 
+// runScenario runs a scenario saved with saveScenario, with its own inputs, settings and seed.
+func runScenario(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+
+	scenario, err := scheduler.LoadScenario(strings.NewReader(args[0].String()))
+	if err != nil {
+		return marshalError(err.Error(), nil, 0, 0)
+	}
+	params := paramsFromScenario(scenario)
+	return schedule(js.ValueOf(scenario.RegistrationsCSV), js.ValueOf(scenario.HallsCSV), &params, startTime)
+}
+
+// schedule runs the scheduler on the registrations and halls inputs with the given params.
+func schedule(regInput, hallsInput js.Value, params *RunParams, startTime time.Time) interface{} {
 	if params.Tries == 0 {
 		params.Tries = 100
 	}
@@ -97,103 +192,869 @@ func runSchedule(this js.Value, args []js.Value) interface{} {
 		params.Timezone = "UTC"
 	}
 
-	// Use provided seed or generate a new one
+	// Use provided seed or generate a new one, within JavaScript's safe integers so that
+	// stats.seed can be passed back as params.seed to repeat the run
 	seed := params.Seed
 	if seed == 0 {
-		seed = time.Now().UnixNano()
+		seed = time.Now().UnixNano()%(1<<53-1) + 1
 	}
 
 	stats := &Stats{Seed: seed, Attempts: params.Tries}
 
 	// 1. Parse Inputs
-	courses, registrations, err := scheduler.ParseRegistrations(regCSV, params.ColumnMapping)
+	// The inputs are hashed for the schedule JSON while they are parsed, so they are read once
+	regSum, hallsSum := sha256.New(), sha256.New()
+	courses, registrations, regDiagnostics, err := parseRegistrationsInput(regInput, params, regSum)
 	if err != nil {
-		return marshalError(fmt.Sprintf("failed to parse registrations CSV: %v", err), nil, seed, time.Since(startTime).Seconds()*1000)
+		return marshalInputError(fmt.Sprintf("failed to parse registrations: %v", err), regDiagnostics, seed, time.Since(startTime).Seconds()*1000)
 	}
-	halls, err := scheduler.ParseHalls(hallsCSV, params.ColumnMapping)
+	halls, hallDiagnostics, err := parseHallsInput(hallsInput, params, hallsSum)
+	diagnostics := append(regDiagnostics, hallDiagnostics...)
 	if err != nil {
-		return marshalError(fmt.Sprintf("failed to parse halls CSV: %v", err), nil, seed, time.Since(startTime).Seconds()*1000)
+		return marshalInputError(fmt.Sprintf("failed to parse halls: %v", err), diagnostics, seed, time.Since(startTime).Seconds()*1000)
 	}
-	allowedSlots, err := scheduler.ParseAllowedSlots(params.AllowedSlotsCSV)
+	constraints, constraintDiagnostics, err := buildConstraints(params)
+	diagnostics = append(diagnostics, constraintDiagnostics...)
 	if err != nil {
-		return marshalError(fmt.Sprintf("failed to parse allowed slots CSV: %v", err), nil, seed, time.Since(startTime).Seconds()*1000)
+		return marshalInputError(err.Error(), diagnostics, seed, time.Since(startTime).Seconds()*1000)
 	}
 
 	// 2. Generate Slots
-	slots, err := scheduler.GenerateSlots(params.ExamStartDate, params.ExamEndDate, params.SlotsPerDay, params.SlotTimes, params.SlotDuration, params.Holidays, params.Timezone)
+	slots, slotDiagnostics, err := buildSlots(params)
+	diagnostics = append(diagnostics, slotDiagnostics...)
+	if err != nil {
+		return marshalInputError(err.Error(), diagnostics, seed, time.Since(startTime).Seconds()*1000)
+	}
+	diagnostics = append(diagnostics, scheduler.CheckAllowedSlots(params.AllowedSlotsCSV, courses, slots)...)
+	diagnostics = append(diagnostics, checkSlotRules(params, slots)...)
+	allowedSlots, err := scheduler.ResolveAllowedSlots(params.AllowedSlotsCSV, slots)
 	if err != nil {
-		return marshalError(fmt.Sprintf("failed to generate slots: %v", err), nil, seed, time.Since(startTime).Seconds()*1000)
+		return marshalInputError(fmt.Sprintf("failed to parse allowed slots CSV: %v", err), diagnostics, seed, time.Since(startTime).Seconds()*1000)
 	}
 
-	// 3. Build Conflict Graph
-	graph := scheduler.NewConflictGraph(courses)
+	// 3. Merge cross-listed courses into shared exams and build the conflict graph
+	exams, examAllowedSlots, examConstraints, err := scheduler.MergeAliases(constraints.Aliases, courses, allowedSlots, constraints)
+	if err != nil {
+		return marshalError(err.Error(), nil, seed, time.Since(startTime).Seconds()*1000)
+	}
+
+	// Split large courses into sittings, each scheduled as its own exam
+	sittings, sittingDiagnostics, err := scheduler.ParseCourseSittingsWithDiagnostics(params.CourseSittingsCSV, params.StrictInput)
+	diagnostics = append(diagnostics, sittingDiagnostics...)
+	if err != nil {
+		return marshalInputError(fmt.Sprintf("failed to parse course sittings CSV: %v", err), diagnostics, seed, time.Since(startTime).Seconds()*1000)
+	}
+	exams, examAllowedSlots, examConstraints, plan, err := scheduler.SplitSittings(sittings, exams, examAllowedSlots, examConstraints)
+	if err != nil {
+		return marshalError(err.Error(), nil, seed, time.Since(startTime).Seconds()*1000)
+	}
+	constraints.Sittings = plan
+	graph := scheduler.NewConflictGraph(exams)
 
 	// 4. Run Scheduler
-	penaltyConfig := scheduler.PenaltyConfig{StudentProximityWeight: 1.0, MinGapViolationWeight: 10.0}
-	result, err := scheduler.RunSchedulingAttempts(params.Tries, seed, courses, halls, slots, allowedSlots, graph, params.MinGap, penaltyConfig)
+	penaltyConfig := buildPenaltyConfig(params)
+	var result *scheduler.ScheduleResult
+	switch params.Objective {
+	case "", "penalty":
+		result, err = scheduler.RunSchedulingAttemptsWithConstraints(params.Tries, seed, exams, halls, slots, examAllowedSlots, graph, params.MinGap, penaltyConfig, examConstraints)
+	case "minDays":
+		result, err = scheduler.MinimizeExamDaysWithConstraints(params.Tries, seed, exams, halls, slots, examAllowedSlots, graph, params.MinGap, penaltyConfig, examConstraints)
+	default:
+		return marshalError(fmt.Sprintf("unknown objective %q", params.Objective), nil, seed, time.Since(startTime).Seconds()*1000)
+	}
 	if err != nil {
 		return marshalError(fmt.Sprintf("scheduling failed: %v", err), nil, seed, time.Since(startTime).Seconds()*1000)
 	}
 
-	// 5. Serialize final schedule
+	// 5. Serialize final schedule, with a row for every code of a shared exam
+	result.Assignments = scheduler.ExpandAliases(result.Assignments, courses, constraints.Aliases)
 	scheduleCSV, err := scheduler.SerializeAssignments(result.Assignments)
 	if err != nil {
 		return marshalError(fmt.Sprintf("failed to serialize schedule: %v", err), nil, seed, time.Since(startTime).Seconds()*1000)
 	}
 
+	var scheduleJSON strings.Builder
+	export := scheduler.NewScheduleExport(result, halls, slots, inputChecksums(regSum, hallsSum, params))
+	if err := scheduler.WriteScheduleJSON(&scheduleJSON, export); err != nil {
+		return marshalError(fmt.Sprintf("failed to serialize schedule: %v", err), nil, seed, time.Since(startTime).Seconds()*1000)
+	}
+
 	// 6. Final verification
-	finalReport, _ := scheduler.VerifySchedule(registrations, scheduleCSV, halls)
+	finalReport, _ := scheduler.VerifyScheduleWithConstraints(registrations, scheduleCSV, halls, slots, constraints)
 	finalReport.CapacityWarnings = result.Report.CapacityWarnings // Carry over warnings from allocation
 
 	// 7. Populate stats and response
 	stats.TotalTime = time.Since(startTime).Seconds() * 1000
 	stats.BestPenalty = result.Penalty
 
-	usedSlots := make(map[scheduler.SlotID]bool)
-	for _, a := range result.Assignments {
-		usedSlots[a.SlotID] = true
+	if result.Window != nil {
+		stats.SlotsUsed = result.Window.SlotsUsed
+		stats.DaysUsed = result.Window.DaysUsed
+		stats.WindowDays = result.Window.Days
+		stats.WindowEnd = result.Window.End.Format(time.RFC3339)
+	}
+
+	response := SuccessResponse{
+		Success:      true,
+		ScheduleCSV:  scheduleCSV,
+		ScheduleJSON: scheduleJSON.String(),
+		Report:       finalReport,
+		Stats:        stats,
+		Diagnostics:  diagnostics,
+	}
+	if len(plan.Sittings) > 0 {
+		response.SittingsCSV, _ = scheduler.SerializeSittings(plan)
+	}
+
+	jsonResponse, _ := json.Marshal(response)
+	return string(jsonResponse)
+}
+
+func repairSchedule(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+
+	publishedInput := args[0]
+	regInput := args[1]
+	hallsInput := args[2]
+	paramsJSON := args[3].String()
+
+	var params RunParams
+	if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
+		return marshalError(fmt.Sprintf("failed to parse params JSON: %v", err), nil, 0, 0)
+	}
+	if params.Timezone == "" {
+		params.Timezone = "UTC"
+	}
+
+	published, err := scheduler.ReadSchedule(inputReader(publishedInput))
+	if err != nil {
+		return marshalError(fmt.Sprintf("failed to parse published schedule CSV: %v", err), nil, 0, time.Since(startTime).Seconds()*1000)
+	}
+	courses, registrations, regDiagnostics, err := parseRegistrationsInput(regInput, &params, nil)
+	if err != nil {
+		return marshalInputError(fmt.Sprintf("failed to parse registrations: %v", err), regDiagnostics, 0, time.Since(startTime).Seconds()*1000)
+	}
+	halls, hallDiagnostics, err := parseHallsInput(hallsInput, &params, nil)
+	diagnostics := append(regDiagnostics, hallDiagnostics...)
+	if err != nil {
+		return marshalInputError(fmt.Sprintf("failed to parse halls: %v", err), diagnostics, 0, time.Since(startTime).Seconds()*1000)
+	}
+	constraints, constraintDiagnostics, err := buildConstraints(&params)
+	diagnostics = append(diagnostics, constraintDiagnostics...)
+	if err != nil {
+		return marshalInputError(err.Error(), diagnostics, 0, time.Since(startTime).Seconds()*1000)
+	}
+	slots, slotDiagnostics, err := buildSlots(&params)
+	diagnostics = append(diagnostics, slotDiagnostics...)
+	if err != nil {
+		return marshalInputError(err.Error(), diagnostics, 0, time.Since(startTime).Seconds()*1000)
+	}
+	diagnostics = append(diagnostics, scheduler.CheckAllowedSlots(params.AllowedSlotsCSV, courses, slots)...)
+	diagnostics = append(diagnostics, checkSlotRules(&params, slots)...)
+	allowedSlots, err := scheduler.ResolveAllowedSlots(params.AllowedSlotsCSV, slots)
+	if err != nil {
+		return marshalInputError(fmt.Sprintf("failed to parse allowed slots CSV: %v", err), diagnostics, 0, time.Since(startTime).Seconds()*1000)
+	}
+
+	// Cross-listed courses move together as one exam
+	exams, examAllowedSlots, examConstraints, err := scheduler.MergeAliases(constraints.Aliases, courses, allowedSlots, constraints)
+	if err != nil {
+		return marshalError(err.Error(), nil, 0, time.Since(startTime).Seconds()*1000)
+	}
+	// Split courses keep their published sittings, so their rows are matched sitting by sitting
+	exams, examAllowedSlots, examConstraints, plan, err := scheduler.SplitSittingsWithPlan(constraints.Sittings, exams, examAllowedSlots, examConstraints)
+	if err != nil {
+		return marshalError(err.Error(), nil, 0, time.Since(startTime).Seconds()*1000)
+	}
+	constraints.Sittings = plan
+	graph := scheduler.NewConflictGraph(exams)
+	penaltyConfig := buildPenaltyConfig(&params)
+	result, changes, err := scheduler.RepairScheduleWithConstraints(published, exams, halls, slots, examAllowedSlots, graph, params.MinGap, penaltyConfig, examConstraints)
+	if err != nil {
+		return marshalError(fmt.Sprintf("repair failed: %v", err), nil, 0, time.Since(startTime).Seconds()*1000)
+	}
+	if len(constraints.Aliases) > 0 {
+		result.Assignments = scheduler.ExpandAliases(result.Assignments, courses, constraints.Aliases)
+		changes = scheduler.DiffSchedules(published, result.Assignments, courses).Changes
+	}
+
+	scheduleCSV, err := scheduler.SerializeAssignments(result.Assignments)
+	if err != nil {
+		return marshalError(fmt.Sprintf("failed to serialize schedule: %v", err), nil, 0, time.Since(startTime).Seconds()*1000)
+	}
+
+	finalReport, _ := scheduler.VerifyScheduleWithConstraints(registrations, scheduleCSV, halls, slots, constraints)
+	finalReport.CapacityWarnings = append(finalReport.CapacityWarnings, result.Report.CapacityWarnings...)
+
+	stats := &Stats{TotalTime: time.Since(startTime).Seconds() * 1000, BestPenalty: result.Penalty}
+	if result.Window != nil {
+		stats.SlotsUsed = result.Window.SlotsUsed
+		stats.DaysUsed = result.Window.DaysUsed
+		stats.WindowDays = result.Window.Days
+		stats.WindowEnd = result.Window.End.Format(time.RFC3339)
 	}
-	stats.SlotsUsed = len(usedSlots)
 
 	response := SuccessResponse{
 		Success:     true,
 		ScheduleCSV: scheduleCSV,
 		Report:      finalReport,
 		Stats:       stats,
+		Changes:     changes,
+		Diagnostics: diagnostics,
 	}
+	if len(plan.Sittings) > 0 {
+		response.SittingsCSV, _ = scheduler.SerializeSittings(plan)
+	}
+	jsonResponse, _ := json.Marshal(response)
+	return string(jsonResponse)
+}
 
+func diffSchedules(this js.Value, args []js.Value) interface{} {
+	beforeCSV := args[0].String()
+	afterCSV := args[1].String()
+
+	// Registrations are optional; without them no affected students are reported. The previous
+	// registrations, if given, also count the students of removed and moved courses.
+	var columnMapping *scheduler.ColumnMapping
+	if len(args) > 3 && hasInput(args[3]) {
+		var params RunParams
+		if err := json.Unmarshal([]byte(args[3].String()), &params); err != nil {
+			return marshalError(fmt.Sprintf("failed to parse params JSON: %v", err), nil, 0, 0)
+		}
+		columnMapping = params.ColumnMapping
+	}
+	courses := make(map[scheduler.CourseID]*scheduler.Course)
+	if len(args) > 2 && hasInput(args[2]) {
+		var err error
+		courses, _, _, err = scheduler.ReadRegistrations(inputReader(args[2]), columnMapping, false)
+		if err != nil {
+			return marshalError(fmt.Sprintf("failed to parse registrations CSV: %v", err), nil, 0, 0)
+		}
+	}
+	var previous map[scheduler.CourseID]*scheduler.Course
+	if len(args) > 4 && hasInput(args[4]) {
+		var err error
+		previous, _, _, err = scheduler.ReadRegistrations(inputReader(args[4]), columnMapping, false)
+		if err != nil {
+			return marshalError(fmt.Sprintf("failed to parse previous registrations CSV: %v", err), nil, 0, 0)
+		}
+	}
+
+	diff, err := scheduler.DiffScheduleCSVsWithPrevious(beforeCSV, afterCSV, previous, courses)
+	if err != nil {
+		return marshalError(fmt.Sprintf("diff failed: %v", err), nil, 0, 0)
+	}
+
+	response := DiffResponse{
+		Success: true,
+		Diff:    diff,
+		Text:    scheduler.FormatDiff(diff),
+	}
 	jsonResponse, _ := json.Marshal(response)
 	return string(jsonResponse)
 }
 
+func assignInvigilators(this js.Value, args []js.Value) interface{} {
+	scheduleCSV := args[0].String()
+	hallsCSV := args[1].String()
+	staffCSV := args[2].String()
+
+	var config scheduler.InvigilationConfig
+	var columnMapping *scheduler.ColumnMapping
+	var sittingsCSV string
+	if len(args) > 3 && args[3].String() != "" {
+		var params struct {
+			scheduler.InvigilationConfig
+			ColumnMapping *scheduler.ColumnMapping `json:"columnMapping,omitempty"`
+			SittingsCSV   string                   `json:"sittingsCSV"`
+		}
+		if err := json.Unmarshal([]byte(args[3].String()), &params); err != nil {
+			return marshalError(fmt.Sprintf("failed to parse params JSON: %v", err), nil, 0, 0)
+		}
+		config = params.InvigilationConfig
+		columnMapping = params.ColumnMapping
+		sittingsCSV = params.SittingsCSV
+	}
+
+	assignments, err := scheduler.ParseSchedule(scheduleCSV)
+	if err != nil {
+		return marshalError(fmt.Sprintf("failed to parse schedule CSV: %v", err), nil, 0, 0)
+	}
+	halls, diagnostics, err := scheduler.ParseHallsWithDiagnostics(hallsCSV, columnMapping, false)
+	if err != nil {
+		return marshalInputError(fmt.Sprintf("failed to parse halls CSV: %v", err), diagnostics, 0, 0)
+	}
+	staff, staffDiagnostics, err := scheduler.ParseStaffWithDiagnostics(staffCSV, false)
+	diagnostics = append(diagnostics, staffDiagnostics...)
+	if err != nil {
+		return marshalInputError(fmt.Sprintf("failed to parse staff CSV: %v", err), diagnostics, 0, 0)
+	}
+	plan, err := scheduler.ParseSittings(sittingsCSV)
+	if err != nil {
+		return marshalError(fmt.Sprintf("failed to parse sittings CSV: %v", err), nil, 0, 0)
+	}
+
+	roster := scheduler.AssignInvigilatorsWithSittings(assignments, halls, staff, config, plan)
+	rosterCSV, err := scheduler.SerializeRoster(roster)
+	if err != nil {
+		return marshalError(fmt.Sprintf("failed to serialize roster: %v", err), nil, 0, 0)
+	}
+
+	response := RosterResponse{
+		Success:     true,
+		RosterCSV:   rosterCSV,
+		Roster:      roster,
+		Report:      scheduler.VerifyRosterWithSittings(roster, assignments, halls, staff, config, plan),
+		Diagnostics: diagnostics,
+	}
+	jsonResponse, _ := json.Marshal(response)
+	return string(jsonResponse)
+}
+
+func exportScheduleXLSX(this js.Value, args []js.Value) interface{} {
+	scheduleCSV := args[0].String()
+	paramsJSON := args[1].String()
+
+	var params RunParams
+	if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
+		return marshalError(fmt.Sprintf("failed to parse params JSON: %v", err), nil, 0, 0)
+	}
+	if params.Timezone == "" {
+		params.Timezone = "UTC"
+	}
+
+	assignments, err := scheduler.ParseSchedule(scheduleCSV)
+	if err != nil {
+		return marshalError(fmt.Sprintf("failed to parse schedule CSV: %v", err), nil, 0, 0)
+	}
+	slots, slotDiagnostics, err := buildSlots(&params)
+	if err != nil {
+		return marshalInputError(err.Error(), slotDiagnostics, 0, 0)
+	}
+	data, err := scheduler.SerializeAssignmentsXLSX(assignments, slots)
+	if err != nil {
+		return marshalError(fmt.Sprintf("failed to write workbook: %v", err), nil, 0, 0)
+	}
+
+	response := ExportResponse{
+		Success: true,
+		XLSX:    base64.StdEncoding.EncodeToString(data),
+	}
+	jsonResponse, _ := json.Marshal(response)
+	return string(jsonResponse)
+}
+
+// exportScheduleHTML renders a printable HTML report of a schedule. Registrations are optional;
+// without them the report has no validation summary.
+func exportScheduleHTML(this js.Value, args []js.Value) interface{} {
+	regInput := args[0]
+	scheduleCSV := args[1].String()
+	hallsInput := args[2]
+	paramsJSON := args[3].String()
+
+	var params RunParams
+	if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
+		return marshalError(fmt.Sprintf("failed to parse params JSON: %v", err), nil, 0, 0)
+	}
+	if params.Timezone == "" {
+		params.Timezone = "UTC"
+	}
+
+	assignments, err := scheduler.ParseSchedule(scheduleCSV)
+	if err != nil {
+		return marshalError(fmt.Sprintf("failed to parse schedule CSV: %v", err), nil, 0, 0)
+	}
+	slots, diagnostics, err := buildSlots(&params)
+	if err != nil {
+		return marshalInputError(err.Error(), diagnostics, 0, 0)
+	}
+	halls, hallDiagnostics, err := parseHallsInput(hallsInput, &params, nil)
+	diagnostics = append(diagnostics, hallDiagnostics...)
+	if err != nil {
+		return marshalInputError(fmt.Sprintf("failed to parse halls: %v", err), diagnostics, 0, 0)
+	}
+
+	var report *scheduler.ValidationReport
+	if hasInput(regInput) {
+		_, registrations, regDiagnostics, err := parseRegistrationsInput(regInput, &params, nil)
+		diagnostics = append(diagnostics, regDiagnostics...)
+		if err != nil {
+			return marshalInputError(fmt.Sprintf("failed to parse registrations: %v", err), diagnostics, 0, 0)
+		}
+		constraints, constraintDiagnostics, err := buildConstraints(&params)
+		diagnostics = append(diagnostics, constraintDiagnostics...)
+		if err != nil {
+			return marshalInputError(err.Error(), diagnostics, 0, 0)
+		}
+		report, _ = scheduler.VerifyScheduleWithConstraints(registrations, scheduleCSV, halls, slots, constraints)
+	}
+
+	var sb strings.Builder
+	if err := scheduler.WriteHTMLReport(&sb, params.ReportTitle, assignments, slots, halls, report); err != nil {
+		return marshalError(fmt.Sprintf("failed to write report: %v", err), nil, 0, 0)
+	}
+
+	response := ReportResponse{
+		Success: true,
+		HTML:    sb.String(),
+	}
+	jsonResponse, _ := json.Marshal(response)
+	return string(jsonResponse)
+}
+
+// saveScenario bundles the inputs and params of a run into a scenario. Workbook inputs are
+// stored as CSV. params.seed must be the seed reported in the run's stats, so the run can be repeated.
+func saveScenario(this js.Value, args []js.Value) interface{} {
+	var params RunParams
+	if err := json.Unmarshal([]byte(args[2].String()), &params); err != nil {
+		return marshalError(fmt.Sprintf("failed to parse params JSON: %v", err), nil, 0, 0)
+	}
+	if params.Seed == 0 {
+		return marshalError("params.seed must be the seed of the run, as reported in its stats", nil, 0, 0)
+	}
+	regCSV, err := inputCSV(args[0], params.RegistrationsFormat, params.RegistrationsSheet)
+	if err != nil {
+		return marshalError(fmt.Sprintf("failed to read registrations: %v", err), nil, 0, 0)
+	}
+	hallsCSV, err := inputCSV(args[1], params.HallsFormat, params.HallsSheet)
+	if err != nil {
+		return marshalError(fmt.Sprintf("failed to read halls: %v", err), nil, 0, 0)
+	}
+
+	var sb strings.Builder
+	if err := scheduler.SaveScenario(&sb, scenarioFromParams(regCSV, hallsCSV, &params)); err != nil {
+		return marshalError(fmt.Sprintf("failed to write scenario: %v", err), nil, 0, 0)
+	}
+	response := ScenarioResponse{
+		Success:  true,
+		Scenario: sb.String(),
+	}
+	jsonResponse, _ := json.Marshal(response)
+	return string(jsonResponse)
+}
+
+// verify checks a schedule against the registrations. The halls and params are optional: with
+// halls, capacities are checked; with params, the schedule is checked against their slots,
+// allowed slots, minimum gap and constraints.
 func verify(this js.Value, args []js.Value) interface{} {
-	regCSV := args[0].String()
 	scheduleCSV := args[1].String()
-	// For verification, we need halls to check capacity, but it's not in the API contract.
-	// Let's assume we need to parse it from somewhere or the contract is flawed.
-	// For now, we'll proceed without hall capacity checks if hallsCSV is not provided.
-	// Let's add a dummy halls slice. A better approach would be to adjust the contract.
-	halls := []*scheduler.Hall{}
+	var hallsInput js.Value
+	if len(args) > 2 {
+		hallsInput = args[2]
+	}
+	var params RunParams
+	if len(args) > 3 && hasInput(args[3]) {
+		if err := json.Unmarshal([]byte(args[3].String()), &params); err != nil {
+			return marshalError(fmt.Sprintf("failed to parse params JSON: %v", err), nil, 0, 0)
+		}
+	}
+	if params.Timezone == "" {
+		params.Timezone = "UTC"
+	}
 
-	_, registrations, err := scheduler.ParseRegistrations(regCSV, nil)
+	_, registrations, diagnostics, err := parseRegistrationsInput(args[0], &params, nil)
+	if err != nil {
+		return marshalInputError(fmt.Sprintf("failed to parse registrations for verification: %v", err), diagnostics, 0, 0)
+	}
+	var halls []*scheduler.Hall
+	if hasInput(hallsInput) {
+		var hallDiagnostics []scheduler.Diagnostic
+		halls, hallDiagnostics, err = parseHallsInput(hallsInput, &params, nil)
+		diagnostics = append(diagnostics, hallDiagnostics...)
+		if err != nil {
+			return marshalInputError(fmt.Sprintf("failed to parse halls for verification: %v", err), diagnostics, 0, 0)
+		}
+	}
+	constraints, constraintDiagnostics, err := buildConstraints(&params)
+	diagnostics = append(diagnostics, constraintDiagnostics...)
 	if err != nil {
-		return marshalError(fmt.Sprintf("failed to parse registrations CSV for verification: %v", err), nil, 0, 0)
+		return marshalInputError(err.Error(), diagnostics, 0, 0)
 	}
 
-	report, err := scheduler.VerifySchedule(registrations, scheduleCSV, halls)
+	// A schedule exported as JSON lists its own halls and slots
+	if strings.HasPrefix(strings.TrimSpace(scheduleCSV), "{") {
+		export, err := scheduler.ReadScheduleJSON(strings.NewReader(scheduleCSV))
+		if err != nil {
+			return marshalError(fmt.Sprintf("verification failed with an error: %v", err), nil, 0, 0)
+		}
+		_, exportSlots, _ := export.Schedule()
+		diagnostics = append(diagnostics, checkSlotRules(&params, exportSlots)...)
+		allowedSlots, err := scheduler.ResolveAllowedSlots(params.AllowedSlotsCSV, exportSlots)
+		if err != nil {
+			return marshalError(fmt.Sprintf("failed to parse allowed slots CSV: %v", err), nil, 0, 0)
+		}
+		report := scheduler.VerifyScheduleExportWithSettings(registrations, export, allowedSlots, params.MinGap, constraints)
+		jsonResponse, _ := json.Marshal(SuccessResponse{Success: true, Report: report, Diagnostics: diagnostics})
+		return string(jsonResponse)
+	}
+
+	var slots []*scheduler.Slot
+	var allowedSlots map[scheduler.CourseID]map[scheduler.SlotID]bool
+	if params.SlotsCSV != "" || params.ExamStartDate != "" {
+		var slotDiagnostics []scheduler.Diagnostic
+		slots, slotDiagnostics, err = buildSlots(&params)
+		diagnostics = append(diagnostics, slotDiagnostics...)
+		if err != nil {
+			return marshalInputError(err.Error(), diagnostics, 0, 0)
+		}
+		diagnostics = append(diagnostics, checkSlotRules(&params, slots)...)
+		if allowedSlots, err = scheduler.ResolveAllowedSlots(params.AllowedSlotsCSV, slots); err != nil {
+			return marshalError(fmt.Sprintf("failed to parse allowed slots CSV: %v", err), nil, 0, 0)
+		}
+	}
+
+	var report *scheduler.ValidationReport
+	if slots != nil {
+		report, err = scheduler.VerifyScheduleWithSettings(registrations, scheduleCSV, halls, slots, allowedSlots, params.MinGap, constraints)
+	} else {
+		// Without slots, only the checks that need no dates can run
+		report, err = scheduler.VerifyScheduleWithConstraints(registrations, scheduleCSV, halls, nil, constraints)
+	}
 	if err != nil {
 		// This error is for catastrophic parsing issues, not validation failures.
 		return marshalError(fmt.Sprintf("verification failed with an error: %v", err), report, 0, 0)
 	}
 
 	response := SuccessResponse{
-		Success: true, // The function succeeded, even if the schedule is invalid
-		Report:  report,
+		Success:     true, // The function succeeded, even if the schedule is invalid
+		Report:      report,
+		Diagnostics: diagnostics,
 	}
 	jsonResponse, _ := json.Marshal(response)
 	return string(jsonResponse)
 }
 
+// parseRegistrationsInput parses the registrations, which are CSV text or a spreadsheet. If sum
+// is not nil, the input is also written to it as it is read.
+func parseRegistrationsInput(input js.Value, params *RunParams, sum hash.Hash) (map[scheduler.CourseID]*scheduler.Course, []scheduler.Registration, []scheduler.Diagnostic, error) {
+	if params.RegistrationsFormat == "" || params.RegistrationsFormat == "csv" {
+		return scheduler.ReadRegistrations(hashedReader(inputReader(input), sum), params.ColumnMapping, params.StrictInput)
+	}
+	sheet, err := readSheet(input, params.RegistrationsFormat, params.RegistrationsSheet, sum)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return scheduler.ParseRegistrationsSheet(sheet, params.ColumnMapping, params.StrictInput)
+}
+
+// parseHallsInput parses the halls, which are CSV text or a spreadsheet, like parseRegistrationsInput.
+func parseHallsInput(input js.Value, params *RunParams, sum hash.Hash) ([]*scheduler.Hall, []scheduler.Diagnostic, error) {
+	if params.HallsFormat == "" || params.HallsFormat == "csv" {
+		return scheduler.ReadHalls(hashedReader(inputReader(input), sum), params.ColumnMapping, params.StrictInput)
+	}
+	sheet, err := readSheet(input, params.HallsFormat, params.HallsSheet, sum)
+	if err != nil {
+		return nil, nil, err
+	}
+	return scheduler.ParseHallsSheet(sheet, params.ColumnMapping, params.StrictInput)
+}
+
+// readSheet reads a workbook passed as a Uint8Array or a base64 string. If sum is not nil, the
+// workbook bytes are written to it.
+func readSheet(input js.Value, format, name string, sum hash.Hash) (*scheduler.Sheet, error) {
+	var raw []byte
+	if isBytes(input) {
+		raw = make([]byte, input.Get("length").Int())
+		js.CopyBytesToGo(raw, input)
+	} else {
+		var err error
+		if raw, err = base64.StdEncoding.DecodeString(input.String()); err != nil {
+			return nil, fmt.Errorf("invalid base64 %s data: %v", format, err)
+		}
+	}
+	if sum != nil {
+		sum.Write(raw)
+	}
+	switch format {
+	case "xlsx":
+		return scheduler.ReadXLSX(raw, name)
+	case "ods":
+		return scheduler.ReadODS(raw, name)
+	}
+	return nil, fmt.Errorf("unknown input format %q", format)
+}
+
+// inputReader reads a CSV input passed as a string or as a Uint8Array. Either is copied into Go
+// a chunk at a time as the parser asks for it, so large files are never held twice.
+func inputReader(input js.Value) io.Reader {
+	if isBytes(input) {
+		return &jsBytesReader{array: input, length: input.Get("length").Int()}
+	}
+	if input.Type() != js.TypeString {
+		return strings.NewReader(input.String())
+	}
+	// A String object, whose length and methods can be used from Go
+	str := js.Global().Get("Object").Invoke(input)
+	return &jsStringReader{str: str, length: str.Length()}
+}
+
+// hashedReader writes everything read from r to sum, unless sum is nil.
+func hashedReader(r io.Reader, sum hash.Hash) io.Reader {
+	if sum == nil {
+		return r
+	}
+	return io.TeeReader(r, sum)
+}
+
+// inputChecksums returns the checksums of the inputs of a run, for the schedule JSON, from the
+// hashes filled while the registrations and halls were parsed.
+func inputChecksums(regSum, hallsSum hash.Hash, params *RunParams) map[string]string {
+	checksums := map[string]string{
+		"registrations": checksum(regSum),
+		"halls":         checksum(hallsSum),
+	}
+	if params.AllowedSlotsCSV != "" {
+		checksums["allowedSlots"], _ = scheduler.Checksum(strings.NewReader(params.AllowedSlotsCSV))
+	}
+	return checksums
+}
+
+// checksum formats a SHA-256 hash like scheduler.Checksum.
+func checksum(sum hash.Hash) string {
+	return "sha256:" + hex.EncodeToString(sum.Sum(nil))
+}
+
+// hasInput reports whether an optional input was passed: a non-empty string or a Uint8Array.
+func hasInput(input js.Value) bool {
+	return isBytes(input) || (input.Type() == js.TypeString && !input.Equal(js.ValueOf("")))
+}
+
+func isBytes(input js.Value) bool {
+	return input.InstanceOf(js.Global().Get("Uint8Array"))
+}
+
+// jsBytesReader is an io.Reader over a JS Uint8Array.
+type jsBytesReader struct {
+	array  js.Value
+	offset int
+	length int
+}
+
+func (r *jsBytesReader) Read(p []byte) (int, error) {
+	if r.offset >= r.length {
+		return 0, io.EOF
+	}
+	end := min(r.offset+len(p), r.length)
+	n := js.CopyBytesToGo(p, r.array.Call("subarray", r.offset, end))
+	r.offset += n
+	return n, nil
+}
+
+// jsStringReader is an io.Reader over a JS string, converted to UTF-8 a chunk at a time.
+type jsStringReader struct {
+	str     js.Value
+	offset  int // In UTF-16 code units, as JS counts
+	length  int
+	pending []byte // Converted but not yet read
+}
+
+// jsStringChunk is the number of UTF-16 code units converted at a time.
+const jsStringChunk = 64 << 10
+
+func (r *jsStringReader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		if r.offset >= r.length {
+			return 0, io.EOF
+		}
+		end := min(r.offset+jsStringChunk, r.length)
+		// Do not split a surrogate pair between chunks
+		if c := r.str.Call("charCodeAt", end-1).Int(); end < r.length && c >= 0xD800 && c <= 0xDBFF {
+			end--
+		}
+		r.pending = []byte(r.str.Call("substring", r.offset, end).String())
+		r.offset = end
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// inputCSV returns an input as CSV text, converting the selected sheet of a workbook.
+func inputCSV(input js.Value, format, sheetName string) (string, error) {
+	if format == "" || format == "csv" {
+		if isBytes(input) {
+			raw := make([]byte, input.Get("length").Int())
+			js.CopyBytesToGo(raw, input)
+			return string(raw), nil
+		}
+		return input.String(), nil
+	}
+	sheet, err := readSheet(input, format, sheetName, nil)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := sheet.WriteCSV(&sb); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func scenarioFromParams(regCSV, hallsCSV string, params *RunParams) *scheduler.Scenario {
+	return &scheduler.Scenario{
+		RegistrationsCSV: regCSV,
+		HallsCSV:         hallsCSV,
+		AllowedSlotsCSV:  params.AllowedSlotsCSV,
+		ColumnMapping:    params.ColumnMapping,
+		StrictInput:      params.StrictInput,
+		Slots: scheduler.ScenarioSlots{
+			StartDate:    params.ExamStartDate,
+			EndDate:      params.ExamEndDate,
+			SlotsPerDay:  params.SlotsPerDay,
+			SlotTimes:    params.SlotTimes,
+			SlotDuration: params.SlotDuration,
+			Holidays:     params.Holidays,
+			Timezone:     params.Timezone,
+			SlotsCSV:     params.SlotsCSV,
+		},
+		Constraints: scheduler.ScenarioConstraints{
+			StudentConstraintsCSV: params.StudentConstraintsCSV,
+			CourseRelationsCSV:    params.CourseRelationsCSV,
+			ForbiddenSlotsCSV:     params.ForbiddenSlotsCSV,
+			CourseRequirementsCSV: params.CourseRequirementsCSV,
+			HallAvailabilityCSV:   params.HallAvailabilityCSV,
+			TravelTimesCSV:        params.TravelTimesCSV,
+			CourseAliasesCSV:      params.CourseAliasesCSV,
+			CourseSittingsCSV:     params.CourseSittingsCSV,
+		},
+		Penalty: scheduler.ScenarioPenalty{
+			StudentProximityWeight: params.StudentProximityWeight,
+			MinGapViolationWeight:  params.MinGapViolationWeight,
+			CampusTravelWeight:     params.CampusTravelWeight,
+		},
+		Seed:      params.Seed,
+		Tries:     params.Tries,
+		MinGap:    params.MinGap,
+		Objective: params.Objective,
+	}
+}
+
+func paramsFromScenario(s *scheduler.Scenario) RunParams {
+	return RunParams{
+		ExamStartDate:          s.Slots.StartDate,
+		ExamEndDate:            s.Slots.EndDate,
+		SlotsPerDay:            s.Slots.SlotsPerDay,
+		SlotTimes:              s.Slots.SlotTimes,
+		SlotDuration:           s.Slots.SlotDuration,
+		Holidays:               s.Slots.Holidays,
+		Tries:                  s.Tries,
+		Seed:                   s.Seed,
+		MinGap:                 s.MinGap,
+		AllowedSlotsCSV:        s.AllowedSlotsCSV,
+		SlotsCSV:               s.Slots.SlotsCSV,
+		Timezone:               s.Slots.Timezone,
+		Objective:              s.Objective,
+		StrictInput:            s.StrictInput,
+		ColumnMapping:          s.ColumnMapping,
+		StudentConstraintsCSV:  s.Constraints.StudentConstraintsCSV,
+		CourseRelationsCSV:     s.Constraints.CourseRelationsCSV,
+		ForbiddenSlotsCSV:      s.Constraints.ForbiddenSlotsCSV,
+		CourseRequirementsCSV:  s.Constraints.CourseRequirementsCSV,
+		HallAvailabilityCSV:    s.Constraints.HallAvailabilityCSV,
+		TravelTimesCSV:         s.Constraints.TravelTimesCSV,
+		CourseAliasesCSV:       s.Constraints.CourseAliasesCSV,
+		CourseSittingsCSV:      s.Constraints.CourseSittingsCSV,
+		StudentProximityWeight: s.Penalty.StudentProximityWeight,
+		MinGapViolationWeight:  s.Penalty.MinGapViolationWeight,
+		CampusTravelWeight:     s.Penalty.CampusTravelWeight,
+	}
+}
+
+// buildPenaltyConfig returns the penalty weights of a run. Weights that are not given take their
+// defaults; a weight of 0 turns its penalty off.
+func buildPenaltyConfig(params *RunParams) scheduler.PenaltyConfig {
+	config := scheduler.PenaltyConfig{StudentProximityWeight: 1.0, MinGapViolationWeight: 10.0, CampusTravelWeight: 10.0}
+	if params.StudentProximityWeight != nil {
+		config.StudentProximityWeight = *params.StudentProximityWeight
+	}
+	if params.MinGapViolationWeight != nil {
+		config.MinGapViolationWeight = *params.MinGapViolationWeight
+	}
+	if params.CampusTravelWeight != nil {
+		config.CampusTravelWeight = *params.CampusTravelWeight
+	}
+	return config
+}
+
+// buildSlots reads the explicit slots, or generates them from the exam period.
+func buildSlots(params *RunParams) ([]*scheduler.Slot, []scheduler.Diagnostic, error) {
+	if params.SlotsCSV != "" {
+		slots, diagnostics, err := scheduler.ParseSlotsWithDiagnostics(params.SlotsCSV, params.Timezone, params.StrictInput)
+		if err != nil {
+			return nil, diagnostics, fmt.Errorf("failed to parse slots CSV: %w", err)
+		}
+		return slots, diagnostics, nil
+	}
+	slots, err := scheduler.GenerateSlots(params.ExamStartDate, params.ExamEndDate, params.SlotsPerDay, params.SlotTimes, params.SlotDuration, params.Holidays, params.Timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate slots: %w", err)
+	}
+	return slots, nil, nil
+}
+
+// checkSlotRules warns about forbidden slot and hall availability rows that match no slot.
+func checkSlotRules(params *RunParams, slots []*scheduler.Slot) []scheduler.Diagnostic {
+	diagnostics := scheduler.CheckForbiddenSlots(params.ForbiddenSlotsCSV, slots)
+	return append(diagnostics, scheduler.CheckHallAvailability(params.HallAvailabilityCSV, slots)...)
+}
+
+// buildConstraints parses the optional constraint inputs of a run, with the diagnostics of every
+// skipped row.
+func buildConstraints(params *RunParams) (*scheduler.Constraints, []scheduler.Diagnostic, error) {
+	var diagnostics []scheduler.Diagnostic
+	strict := params.StrictInput
+
+	students, d, err := scheduler.ParseStudentConstraintsWithDiagnostics(params.StudentConstraintsCSV, strict)
+	diagnostics = append(diagnostics, d...)
+	if err != nil {
+		return nil, diagnostics, fmt.Errorf("failed to parse student constraints CSV: %w", err)
+	}
+	relations, d, err := scheduler.ParseCourseRelationsWithDiagnostics(params.CourseRelationsCSV, strict)
+	diagnostics = append(diagnostics, d...)
+	if err != nil {
+		return nil, diagnostics, fmt.Errorf("failed to parse course relations CSV: %w", err)
+	}
+	forbidden, d, err := scheduler.ParseForbiddenSlotsWithDiagnostics(params.ForbiddenSlotsCSV, strict)
+	diagnostics = append(diagnostics, d...)
+	if err != nil {
+		return nil, diagnostics, fmt.Errorf("failed to parse forbidden slots CSV: %w", err)
+	}
+	requirements, d, err := scheduler.ParseCourseRequirementsWithDiagnostics(params.CourseRequirementsCSV, strict)
+	diagnostics = append(diagnostics, d...)
+	if err != nil {
+		return nil, diagnostics, fmt.Errorf("failed to parse course requirements CSV: %w", err)
+	}
+	hallWindows, d, err := scheduler.ParseHallAvailabilityWithDiagnostics(params.HallAvailabilityCSV, strict)
+	diagnostics = append(diagnostics, d...)
+	if err != nil {
+		return nil, diagnostics, fmt.Errorf("failed to parse hall availability CSV: %w", err)
+	}
+	travel, d, err := scheduler.ParseTravelTimesWithDiagnostics(params.TravelTimesCSV, strict)
+	diagnostics = append(diagnostics, d...)
+	if err != nil {
+		return nil, diagnostics, fmt.Errorf("failed to parse travel times CSV: %w", err)
+	}
+	aliases, d, err := scheduler.ParseCourseAliasesWithDiagnostics(params.CourseAliasesCSV, strict)
+	diagnostics = append(diagnostics, d...)
+	if err != nil {
+		return nil, diagnostics, fmt.Errorf("failed to parse course aliases CSV: %w", err)
+	}
+	sittings, err := scheduler.ParseSittings(params.SittingsCSV)
+	if err != nil {
+		return nil, diagnostics, fmt.Errorf("failed to parse sittings CSV: %w", err)
+	}
+	return &scheduler.Constraints{
+		Students:     students,
+		Relations:    relations,
+		Forbidden:    forbidden,
+		Requirements: requirements,
+		HallWindows:  hallWindows,
+		Travel:       travel,
+		Aliases:      aliases,
+		Sittings:     sittings,
+	}, diagnostics, nil
+}
+
 func marshalError(errMsg string, report *scheduler.ValidationReport, seed int64, totalTime float64) string {
 	errResp := ErrorResponse{
 		Success: false,
@@ -204,3 +1065,14 @@ func marshalError(errMsg string, report *scheduler.ValidationReport, seed int64,
 	jsonResponse, _ := json.Marshal(errResp)
 	return string(jsonResponse)
 }
+
+func marshalInputError(errMsg string, diagnostics []scheduler.Diagnostic, seed int64, totalTime float64) string {
+	errResp := ErrorResponse{
+		Success:     false,
+		Error:       errMsg,
+		Stats:       &Stats{Seed: seed, TotalTime: totalTime},
+		Diagnostics: diagnostics,
+	}
+	jsonResponse, _ := json.Marshal(errResp)
+	return string(jsonResponse)
+}
@@ -0,0 +1,325 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CourseAliases maps course codes that share one physical exam, such as cross-listed courses
+// (CS301/ECE301) or sections (CS101-A/CS101-B), to the ID of that exam.
+type CourseAliases map[CourseID]CourseID
+
+// Exam returns the ID of the exam the course is sat in; a course without an alias is its own exam.
+func (ca CourseAliases) Exam(courseID CourseID) CourseID {
+	if exam, ok := ca[courseID]; ok {
+		return exam
+	}
+	return courseID
+}
+
+// ParseCourseAliases parses the course aliases CSV data.
+// Required columns are course_id and exam_id; every course with the same exam_id is merged
+// into one exam. The exam_id may itself be one of the course codes. Chains are followed, and a
+// course mapped to two different exams is an error. Rows that cannot be used are skipped; see
+// ParseCourseAliasesWithDiagnostics to find out which.
+func ParseCourseAliases(csvData string) (CourseAliases, error) {
+	aliases, _, err := ParseCourseAliasesWithDiagnostics(csvData, false)
+	return aliases, err
+}
+
+// ParseCourseAliasesWithDiagnostics parses the course aliases CSV data like ParseCourseAliases
+// and also reports every skipped row. In strict mode any skipped row is an error.
+func ParseCourseAliasesWithDiagnostics(csvData string, strict bool) (CourseAliases, []Diagnostic, error) {
+	if csvData == "" {
+		return nil, nil, nil
+	}
+
+	table, err := newCSVTable("course aliases", csvData, "course_id", "exam_id")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	direct := make(map[CourseID]CourseID)
+	for table.next() {
+		courseID := CourseID(table.field("course_id"))
+		examID := CourseID(table.field("exam_id"))
+		if courseID == "" {
+			table.skip("course_id", "empty course ID")
+			continue
+		}
+		if examID == "" {
+			table.skip("exam_id", "empty exam ID")
+			continue
+		}
+		if prev, ok := direct[courseID]; ok && prev != examID {
+			return nil, nil, fmt.Errorf("course %s is mapped to both exam %s and exam %s", courseID, prev, examID)
+		}
+		direct[courseID] = examID
+	}
+
+	aliases := make(CourseAliases, len(direct))
+	for courseID := range direct {
+		exam, seen := courseID, map[CourseID]bool{courseID: true}
+		for {
+			next, ok := direct[exam]
+			if !ok || next == exam {
+				break
+			}
+			if seen[next] {
+				return nil, nil, fmt.Errorf("course aliases for %s form a cycle", courseID)
+			}
+			seen[next] = true
+			exam = next
+		}
+		aliases[courseID] = exam
+	}
+	diagnostics, err := table.result(strict)
+	if err != nil {
+		return nil, diagnostics, err
+	}
+	return aliases, diagnostics, nil
+}
+
+// members returns the courses that are sat in each exam with more than one course, sorted.
+func (ca CourseAliases) members(courses map[CourseID]*Course) map[CourseID][]CourseID {
+	byExam := make(map[CourseID][]CourseID)
+	for courseID := range courses {
+		exam := ca.Exam(courseID)
+		byExam[exam] = append(byExam[exam], courseID)
+	}
+	for exam, ids := range byExam {
+		if len(ids) < 2 && ids[0] == exam {
+			delete(byExam, exam)
+			continue
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+	return byExam
+}
+
+// MergeAliases combines aliased courses into one course per exam, so that they are coloured
+// and seated together. Allowed slots of the merged courses are intersected, and course-level
+// constraints are moved to the exam. The returned constraints keep the aliases, so verification
+// can merge the expanded schedule again. Use ExpandAliases to get back one row per course code.
+func MergeAliases(
+	aliases CourseAliases,
+	courses map[CourseID]*Course,
+	allowedSlots map[CourseID]map[SlotID]bool,
+	constraints *Constraints,
+) (map[CourseID]*Course, map[CourseID]map[SlotID]bool, *Constraints, error) {
+	if len(aliases) == 0 {
+		return courses, allowedSlots, constraints, nil
+	}
+
+	merged := make(map[CourseID]*Course, len(courses))
+	for courseID, course := range courses {
+		exam := aliases.Exam(courseID)
+		if merged[exam] == nil {
+			merged[exam] = &Course{ID: exam}
+		}
+		merged[exam].Enrollments = append(merged[exam].Enrollments, course.Enrollments...)
+	}
+	for _, course := range merged {
+		course.Enrollments = sortedStudents(course.Enrollments)
+	}
+
+	mergedAllowed := make(map[CourseID]map[SlotID]bool, len(allowedSlots))
+	for courseID, allowed := range allowedSlots {
+		if len(allowed) == 0 {
+			continue
+		}
+		exam := aliases.Exam(courseID)
+		current, ok := mergedAllowed[exam]
+		if !ok {
+			mergedAllowed[exam] = allowed
+			continue
+		}
+		both := make(map[SlotID]bool)
+		for slotID := range allowed {
+			if current[slotID] {
+				both[slotID] = true
+			}
+		}
+		if len(both) == 0 {
+			return nil, nil, nil, fmt.Errorf("courses sharing exam %s have no allowed slot in common", exam)
+		}
+		mergedAllowed[exam] = both
+	}
+
+	return merged, mergedAllowed, constraints.withAliases(aliases, courses), nil
+}
+
+// withAliases returns a copy of the constraints with course-level rules moved to the exams.
+// Department rules are kept and also added for each exam that contains a course of the department.
+func (c *Constraints) withAliases(aliases CourseAliases, courses map[CourseID]*Course) *Constraints {
+	if c == nil {
+		return &Constraints{Aliases: aliases}
+	}
+	out := *c
+	out.Aliases = aliases
+
+	out.Relations = make([]CourseRelation, len(c.Relations))
+	for i, r := range c.Relations {
+		r.A, r.B = aliases.Exam(r.A), aliases.Exam(r.B)
+		out.Relations[i] = r
+	}
+
+	out.Forbidden = nil
+	for _, f := range c.Forbidden {
+		if f.CourseID != "" {
+			f.CourseID = aliases.Exam(f.CourseID)
+			out.Forbidden = append(out.Forbidden, f)
+			continue
+		}
+		out.Forbidden = append(out.Forbidden, f)
+		for courseID := range courses {
+			if exam := aliases.Exam(courseID); exam != courseID && f.Applies(courseID) && !f.Applies(exam) {
+				out.Forbidden = append(out.Forbidden, ForbiddenSlot{CourseID: exam, Pattern: f.Pattern})
+			}
+		}
+	}
+	out.Forbidden = uniqueForbidden(out.Forbidden)
+
+	if c.Requirements != nil {
+		out.Requirements = make(map[CourseID][]string, len(c.Requirements))
+		for courseID, features := range c.Requirements {
+			exam := aliases.Exam(courseID)
+			out.Requirements[exam] = mergeFeatures(out.Requirements[exam], features)
+		}
+	}
+	return &out
+}
+
+// uniqueForbidden drops repeated rules, which appear when constraints are merged twice.
+func uniqueForbidden(rules []ForbiddenSlot) []ForbiddenSlot {
+	seen := make(map[string]bool, len(rules))
+	out := rules[:0]
+	for _, f := range rules {
+		key := string(f.CourseID) + "\x00" + f.Department + "\x00" + f.Pattern.String()
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// ExpandAliases turns the assignment of each merged exam back into one assignment per course
+// code, sharing the exam's slot and halls. courses are the original, unmerged courses.
+func ExpandAliases(assignments []*Assignment, courses map[CourseID]*Course, aliases CourseAliases) []*Assignment {
+	if len(aliases) == 0 {
+		return assignments
+	}
+	members := aliases.members(courses)
+
+	expanded := make([]*Assignment, 0, len(assignments))
+	for _, a := range assignments {
+		ids, ok := members[a.CourseID]
+		if !ok {
+			expanded = append(expanded, a)
+			continue
+		}
+		for _, courseID := range ids {
+			var others []string
+			for _, other := range ids {
+				if other != courseID {
+					others = append(others, string(other))
+				}
+			}
+			row := *a
+			row.CourseID = courseID
+			row.EnrolledCount = len(courses[courseID].Enrollments)
+			if len(others) > 0 {
+				row.Notes = "shared exam with " + strings.Join(others, ";")
+			}
+			expanded = append(expanded, &row)
+		}
+	}
+
+	sort.Slice(expanded, func(i, j int) bool {
+		if expanded[i].SlotDateTime != expanded[j].SlotDateTime {
+			return expanded[i].SlotDateTime < expanded[j].SlotDateTime
+		}
+		return expanded[i].CourseID < expanded[j].CourseID
+	})
+	return expanded
+}
+
+// mergeAliasedAssignments folds the rows of aliased courses back into one assignment per exam
+// and maps registrations to exams, for verification. A merged exam counts each of its students
+// once, even one registered for several of its course codes. Aliased courses placed in
+// different slots are reported as violations.
+func mergeAliasedAssignments(
+	report *ValidationReport,
+	registrations []Registration,
+	assignments []*Assignment,
+	aliases CourseAliases,
+) ([]Registration, []*Assignment) {
+	if len(aliases) == 0 {
+		return registrations, assignments
+	}
+
+	type examRow struct {
+		assignment *Assignment
+		first      CourseID
+		rows       int
+		halls      []string
+	}
+	exams := make(map[CourseID]*examRow)
+	var order []CourseID
+	for _, a := range assignments {
+		exam := aliases.Exam(a.CourseID)
+		row, ok := exams[exam]
+		if !ok {
+			merged := *a
+			merged.CourseID = exam
+			row = &examRow{assignment: &merged, first: a.CourseID}
+			exams[exam] = row
+			order = append(order, exam)
+		} else {
+			if row.assignment.SlotID != a.SlotID {
+				report.ConstraintViolations = append(report.ConstraintViolations,
+					fmt.Sprintf("courses %s and %s share exam %s but are in slots %s and %s", row.first, a.CourseID, exam, row.assignment.SlotID, a.SlotID))
+			}
+			row.assignment.EnrolledCount += a.EnrolledCount
+		}
+		row.rows++
+		for _, h := range strings.Split(a.Halls, ";") {
+			if h != "" && !containsString(row.halls, h) {
+				row.halls = append(row.halls, h)
+			}
+		}
+	}
+
+	merged := make([]*Assignment, 0, len(order))
+	for _, exam := range order {
+		row := exams[exam]
+		sort.Strings(row.halls)
+		row.assignment.Halls = strings.Join(row.halls, ";")
+		merged = append(merged, row.assignment)
+	}
+
+	seen := make(map[Registration]bool, len(registrations))
+	mergedRegs := make([]Registration, 0, len(registrations))
+	for _, reg := range registrations {
+		reg.CourseID = aliases.Exam(reg.CourseID)
+		if !seen[reg] {
+			seen[reg] = true
+			mergedRegs = append(mergedRegs, reg)
+		}
+	}
+
+	if len(registrations) > 0 {
+		students := make(map[CourseID]int)
+		for _, reg := range mergedRegs {
+			students[reg.CourseID]++
+		}
+		for _, a := range merged {
+			if exams[a.CourseID].rows > 1 {
+				a.EnrolledCount = students[a.CourseID]
+			}
+		}
+	}
+	return mergedRegs, merged
+}
@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"testing"
+)
+
+func TestParseCourseAliases(t *testing.T) {
+	aliases, err := ParseCourseAliases(`course_id,exam_id
+ECE301,CS301
+CS101-A,CS101
+CS101-B,CS101
+CS101,INTRO
+`)
+	if err != nil {
+		t.Fatalf("ParseCourseAliases failed: %v", err)
+	}
+	if aliases.Exam("ECE301") != "CS301" || aliases.Exam("CS301") != "CS301" {
+		t.Errorf("unexpected exam for the CS301 cross-listing: %v", aliases)
+	}
+	if aliases.Exam("CS101-A") != "INTRO" || aliases.Exam("CS101") != "INTRO" {
+		t.Errorf("expected the chain to resolve to INTRO: %v", aliases)
+	}
+
+	if _, err := ParseCourseAliases("course_id,exam_id\nA,X\nA,Y\n"); err == nil {
+		t.Error("expected an error for a course mapped to two exams")
+	}
+	if _, err := ParseCourseAliases("course_id,exam_id\nA,B\nB,A\n"); err == nil {
+		t.Error("expected an error for a cycle")
+	}
+}
+
+func TestMergeAndExpandAliases(t *testing.T) {
+	courses, regs, _ := ParseRegistrations(`student_id,course_id
+s1,CS301
+s2,ECE301
+s3,ECE301
+s3,MA101
+`, nil)
+	aliases := CourseAliases{"ECE301": "CS301"}
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-06", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	allowed := map[CourseID]map[SlotID]bool{
+		"CS301":  {slots[0].ID: true, slots[1].ID: true},
+		"ECE301": {slots[1].ID: true},
+	}
+	constraints := &Constraints{Requirements: map[CourseID][]string{"ECE301": {"lab"}}}
+
+	exams, examAllowed, examConstraints, err := MergeAliases(aliases, courses, allowed, constraints)
+	if err != nil {
+		t.Fatalf("MergeAliases failed: %v", err)
+	}
+	if len(exams) != 2 || len(exams["CS301"].Enrollments) != 3 {
+		t.Fatalf("expected CS301 to hold all 3 students of the shared exam, got %+v", exams)
+	}
+	if len(examAllowed["CS301"]) != 1 || !examAllowed["CS301"][slots[1].ID] {
+		t.Errorf("expected the allowed slots to be intersected, got %v", examAllowed["CS301"])
+	}
+	if got := examConstraints.RequiredFeatures("CS301"); len(got) != 1 || got[0] != "lab" {
+		t.Errorf("expected the lab requirement to move to the exam, got %v", got)
+	}
+
+	halls := []*Hall{{ID: "Lab", Capacity: 10, Features: []string{"lab"}}, {ID: "H1", Capacity: 10}}
+	result, err := RunSchedulingAttemptsWithConstraints(5, 42, exams, halls, slots, examAllowed, NewConflictGraph(exams), 0, PenaltyConfig{}, examConstraints)
+	if err != nil {
+		t.Fatalf("RunSchedulingAttempts failed: %v", err)
+	}
+	if len(result.Assignments) != 2 {
+		t.Fatalf("expected 2 exams to be scheduled, got %d", len(result.Assignments))
+	}
+
+	expanded := ExpandAliases(result.Assignments, courses, aliases)
+	if len(expanded) != 3 {
+		t.Fatalf("expected 3 rows after expansion, got %d", len(expanded))
+	}
+	rows := make(map[CourseID]*Assignment)
+	for _, a := range expanded {
+		rows[a.CourseID] = a
+	}
+	if rows["CS301"].SlotID != rows["ECE301"].SlotID || rows["CS301"].Halls != "Lab" || rows["ECE301"].Halls != "Lab" {
+		t.Errorf("expected CS301 and ECE301 to share slot and hall, got %+v and %+v", rows["CS301"], rows["ECE301"])
+	}
+	if rows["ECE301"].EnrolledCount != 2 || rows["ECE301"].Notes != "shared exam with CS301" {
+		t.Errorf("unexpected ECE301 row: %+v", rows["ECE301"])
+	}
+
+	scheduleCSV, _ := SerializeAssignments(expanded)
+	report, err := VerifyScheduleWithConstraints(regs, scheduleCSV, halls, slots, &Constraints{Aliases: aliases})
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithConstraints failed: %v", err)
+	}
+	if !report.Valid || len(report.Errors) != 0 || len(report.Unassigned) != 0 {
+		t.Errorf("expected the expanded schedule to verify, got %+v", report)
+	}
+}
+
+func TestVerifyScheduleWithConstraints_AliasesInDifferentSlots(t *testing.T) {
+	_, regs, _ := ParseRegistrations(`student_id,course_id
+s1,CS301
+s2,ECE301
+`, nil)
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-06", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	scheduleCSV := `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+CS301,2025-01-06T09:00Z#1,2025-01-06T09:00:00Z,H1,1,
+ECE301,2025-01-06T14:00Z#2,2025-01-06T14:00:00Z,H1,1,
+`
+	halls := []*Hall{{ID: "H1", Capacity: 10}}
+
+	report, err := VerifyScheduleWithConstraints(regs, scheduleCSV, halls, slots, &Constraints{Aliases: CourseAliases{"ECE301": "CS301"}})
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithConstraints failed: %v", err)
+	}
+	if report.Valid || len(report.ConstraintViolations) != 1 {
+		t.Errorf("expected a violation for split aliases, got %+v", report)
+	}
+}
+
+func TestVerifyScheduleWithConstraints_AliasesCountStudentsOnce(t *testing.T) {
+	// s1 is registered under both codes of the shared exam and takes one seat.
+	_, regs, _ := ParseRegistrations(`student_id,course_id
+s1,CS301
+s1,ECE301
+s2,ECE301
+`, nil)
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-06", 1, []string{"09:00"}, 180, nil, "UTC")
+	scheduleCSV := `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+CS301,2025-01-06T09:00Z#1,2025-01-06T09:00:00Z,H1,1,shared exam with ECE301
+ECE301,2025-01-06T09:00Z#1,2025-01-06T09:00:00Z,H1,2,shared exam with CS301
+`
+	halls := []*Hall{{ID: "H1", Capacity: 2}}
+
+	report, err := VerifyScheduleWithConstraints(regs, scheduleCSV, halls, slots, &Constraints{Aliases: CourseAliases{"ECE301": "CS301"}})
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithConstraints failed: %v", err)
+	}
+	if !report.Valid || len(report.CapacityWarnings) != 0 {
+		t.Errorf("expected the 2 students of the shared exam to fit in 2 seats, got %+v", report)
+	}
+}
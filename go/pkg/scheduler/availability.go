@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HallWindow makes a hall available or unavailable in the slots matched by a pattern.
+// Halls are available by default; when several windows of a hall match a slot, the last one wins,
+// so "unavailable" on a date followed by "available" for that date's afternoon frees the afternoon only.
+type HallWindow struct {
+	HallID    HallID
+	Pattern   SlotPattern
+	Available bool
+}
+
+// String describes the window for reports.
+func (w HallWindow) String() string {
+	if w.Available {
+		return fmt.Sprintf("hall %s available in %q", w.HallID, w.Pattern)
+	}
+	return fmt.Sprintf("hall %s unavailable in %q", w.HallID, w.Pattern)
+}
+
+// ParseHallAvailability parses the hall availability CSV data.
+// Required columns are hall and slot (anything ParseSlotPattern accepts); the optional available
+// column holds available/unavailable or yes/no and defaults to unavailable. Rows keep their order.
+// Rows that cannot be used are skipped; see ParseHallAvailabilityWithDiagnostics to find out which.
+func ParseHallAvailability(csvData string) ([]HallWindow, error) {
+	windows, _, err := ParseHallAvailabilityWithDiagnostics(csvData, false)
+	return windows, err
+}
+
+// ParseHallAvailabilityWithDiagnostics parses the hall availability CSV data like
+// ParseHallAvailability and also reports every skipped row. In strict mode any skipped row is an error.
+func ParseHallAvailabilityWithDiagnostics(csvData string, strict bool) ([]HallWindow, []Diagnostic, error) {
+	if csvData == "" {
+		return nil, nil, nil
+	}
+
+	table, err := newCSVTable("hall availability", csvData, "hall", "slot")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var windows []HallWindow
+	for table.next() {
+		window := HallWindow{HallID: HallID(table.field("hall"))}
+		if window.HallID == "" {
+			table.skip("hall", "empty hall ID")
+			continue
+		}
+
+		window.Pattern, err = ParseSlotPattern(table.field("slot"))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid slot for hall %s: %w", window.HallID, err)
+		}
+
+		switch v := strings.ToLower(table.field("available")); v {
+		case "available", "free":
+			window.Available = true
+		case "", "unavailable", "booked":
+			window.Available = false
+		default:
+			window.Available = parseFlag(v)
+			if !window.Available && !isFalseFlag(v) {
+				return nil, nil, fmt.Errorf("invalid availability %q for hall %s", v, window.HallID)
+			}
+		}
+		windows = append(windows, window)
+	}
+
+	diagnostics, err := table.result(strict)
+	if err != nil {
+		return nil, diagnostics, err
+	}
+	return windows, diagnostics, nil
+}
+
+// CheckHallAvailability reports hall availability CSV rows whose slot pattern matches none of the
+// slots, which usually means a typo. Such rows change no hall's availability, so they are warnings.
+func CheckHallAvailability(csvData string, slots []*Slot) []Diagnostic {
+	return checkSlotPatterns("hall availability", csvData, "slot", slots)
+}
+
+// isFalseFlag reports whether a CSV cell is an explicit negative flag.
+func isFalseFlag(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "0", "false", "no", "n":
+		return true
+	}
+	return false
+}
+
+// HallAvailable reports whether the hall may be used in the slot.
+func (c *Constraints) HallAvailable(hallID HallID, slot *Slot) bool {
+	if c == nil {
+		return true
+	}
+	available := true
+	for _, w := range c.HallWindows {
+		if w.HallID == hallID && w.Pattern.Matches(slot) {
+			available = w.Available
+		}
+	}
+	return available
+}
+
+// UnavailableHalls returns the halls that are outside their availability windows in the slot,
+// or nil if every hall is available.
+func (c *Constraints) UnavailableHalls(halls []*Hall, slot *Slot) map[HallID]bool {
+	if c == nil || len(c.HallWindows) == 0 {
+		return nil
+	}
+	var unavailable map[HallID]bool
+	for _, h := range halls {
+		if !c.HallAvailable(h.ID, slot) {
+			if unavailable == nil {
+				unavailable = make(map[HallID]bool)
+			}
+			unavailable[h.ID] = true
+		}
+	}
+	return unavailable
+}
@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"testing"
+)
+
+func TestParseHallAvailability(t *testing.T) {
+	windows, err := ParseHallAvailability(`hall,slot,available
+Sports,Wed,
+Theatre,2025-01-06,no
+Theatre,2025-01-06 afternoon,available
+`)
+	if err != nil {
+		t.Fatalf("ParseHallAvailability failed: %v", err)
+	}
+	if len(windows) != 3 {
+		t.Fatalf("expected 3 windows, got %d", len(windows))
+	}
+	if windows[0].Available || windows[1].Available || !windows[2].Available {
+		t.Errorf("unexpected availability: %+v", windows)
+	}
+
+	if _, err := ParseHallAvailability("hall,slot,available\nSports,Wed,maybe\n"); err == nil {
+		t.Error("expected an error for an invalid availability value")
+	}
+	if _, err := ParseHallAvailability("hall,available\nSports,no\n"); err == nil {
+		t.Error("expected an error for a missing slot column")
+	}
+}
+
+func TestCheckHallAvailability(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-08", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	diags := CheckHallAvailability(`hall,slot,available
+Sports,Wed,no
+Theatre,Fri,no
+`, slots)
+	if len(diags) != 1 || diags[0].String() != `hall availability:3: slot: warning: "Fri" matches no slot, so the row has no effect` {
+		t.Errorf("expected a warning for the Friday row, got %v", diags)
+	}
+}
+
+func TestConstraints_HallAvailable(t *testing.T) {
+	// 2025-01-06 is a Monday and 2025-01-08 a Wednesday.
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-08", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	windows, _ := ParseHallAvailability(`hall,slot,available
+Sports,Wed,no
+Theatre,2025-01-06,no
+Theatre,2025-01-06 afternoon,yes
+`)
+	constraints := &Constraints{HallWindows: windows}
+
+	cases := []struct {
+		hall      HallID
+		slot      int
+		available bool
+	}{
+		{"Sports", 0, true},
+		{"Sports", 4, false},
+		{"Sports", 5, false},
+		{"Theatre", 0, false},
+		{"Theatre", 1, true},
+		{"Theatre", 2, true},
+		{"Other", 4, true},
+	}
+	for _, c := range cases {
+		if got := constraints.HallAvailable(c.hall, slots[c.slot]); got != c.available {
+			t.Errorf("HallAvailable(%s, %s) = %v, want %v", c.hall, slots[c.slot].ID, got, c.available)
+		}
+	}
+
+	halls := []*Hall{{ID: "Sports"}, {ID: "Theatre"}, {ID: "Other"}}
+	if unavailable := constraints.UnavailableHalls(halls, slots[4]); len(unavailable) != 1 || !unavailable["Sports"] {
+		t.Errorf("expected only Sports to be unavailable on Wednesday, got %v", unavailable)
+	}
+	if unavailable := (*Constraints)(nil).UnavailableHalls(halls, slots[4]); unavailable != nil {
+		t.Errorf("expected no unavailable halls without constraints, got %v", unavailable)
+	}
+}
@@ -0,0 +1,317 @@
+package scheduler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BenchmarkInstance is a problem instance from the exam timetabling literature, converted into
+// the scheduler's inputs.
+type BenchmarkInstance struct {
+	Courses       map[CourseID]*Course
+	Registrations []Registration
+	Halls         []*Hall                      // None for uncapacitated instances
+	Slots         []*Slot                      // Sorted by start time; a slot's index is its period
+	AllowedSlots  map[CourseID]map[SlotID]bool // Nil if every course may use every slot
+	Constraints   *Constraints                 // Nil if the instance has no hard constraints beyond clashes
+	Weightings    map[string][]int             // ITC2007 institutional weightings, for reference
+}
+
+// ReadToronto reads a Carter/Toronto benchmark instance from its .crs file, with a course ID and
+// enrollment per line, and its .stu file, with the courses of one student per line. Students are
+// numbered by their line. The instances have no halls and no dates, only a number of periods;
+// they are laid out slotsPerDay to a day, each day starting at 09:00 with four hours per slot.
+// Enrollments in the .crs file are not checked, as published versions of some instances disagree
+// with their .stu files.
+func ReadToronto(crs, stu io.Reader, periods, slotsPerDay int) (*BenchmarkInstance, error) {
+	if periods < 1 || slotsPerDay < 1 {
+		return nil, fmt.Errorf("invalid number of periods %d or slots per day %d", periods, slotsPerDay)
+	}
+
+	courses := make(map[CourseID]*Course)
+	scanner := bufio.NewScanner(crs)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue // Skip blank lines
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("crs line %d: expected a course ID and an enrollment, got %q", line, scanner.Text())
+		}
+		courseID := CourseID(fields[0])
+		courses[courseID] = &Course{ID: courseID}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	instance := &BenchmarkInstance{Courses: courses}
+	seen := make(map[Registration]bool)
+	scanner = bufio.NewScanner(stu)
+	scanner.Buffer(nil, 1<<20)
+	student := 0
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue // Skip blank lines
+		}
+		student++
+		studentID := StudentID(strconv.Itoa(student))
+		for _, f := range fields {
+			courseID := CourseID(f)
+			course, ok := courses[courseID]
+			if !ok {
+				return nil, fmt.Errorf("stu line %d: course %s is not in the crs file", line, courseID)
+			}
+			reg := Registration{StudentID: studentID, CourseID: courseID}
+			if seen[reg] {
+				continue // Some instances list a course twice for a student
+			}
+			seen[reg] = true
+			instance.Registrations = append(instance.Registrations, reg)
+			course.Enrollments = append(course.Enrollments, studentID)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	base := time.Date(2000, time.January, 3, 9, 0, 0, 0, time.UTC)
+	for p := 0; p < periods; p++ {
+		day, index := p/slotsPerDay, p%slotsPerDay
+		start := base.AddDate(0, 0, day).Add(time.Duration(index) * 4 * time.Hour)
+		instance.Slots = append(instance.Slots, &Slot{
+			ID:         slotIDFor(start, index),
+			Start:      start,
+			End:        start.Add(3 * time.Hour),
+			DayIndex:   day,
+			IndexInDay: index,
+		})
+	}
+	return instance, nil
+}
+
+// ReadITC2007 reads an instance of the ITC2007 examination track. Exams become courses named by
+// their index, periods become slots and rooms become halls named R0, R1 and so on. Hard
+// constraints are kept: exams only get periods long enough for them, EXAM_COINCIDENCE,
+// EXCLUSION and AFTER become course relations, and ROOM_EXCLUSIVE always holds since halls are
+// never shared. Soft constraints are kept in Weightings only; period and room penalties are
+// dropped.
+func ReadITC2007(r io.Reader) (*BenchmarkInstance, error) {
+	instance := &BenchmarkInstance{
+		Courses:    make(map[CourseID]*Course),
+		Weightings: make(map[string][]int),
+	}
+	var durations []int
+	var relations []CourseRelation
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24) // Exam lines list every student
+	section := ""
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "[") {
+			section, _, _ = strings.Cut(strings.Trim(text, "[]"), ":")
+			continue
+		}
+
+		fields := strings.Split(text, ",")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		number := func(i int) (int, error) {
+			if i >= len(fields) {
+				return 0, fmt.Errorf("line %d: expected at least %d values, got %q", line, i+1, text)
+			}
+			n, err := strconv.Atoi(fields[i])
+			if err != nil {
+				return 0, fmt.Errorf("line %d: invalid number %q", line, fields[i])
+			}
+			return n, nil
+		}
+
+		switch section {
+		case "Exams":
+			duration, err := number(0)
+			if err != nil {
+				return nil, err
+			}
+			courseID := CourseID(strconv.Itoa(len(durations)))
+			course := &Course{ID: courseID}
+			seen := make(map[StudentID]bool)
+			for i := 1; i < len(fields); i++ {
+				if fields[i] == "" {
+					continue
+				}
+				studentID := StudentID(fields[i])
+				if seen[studentID] {
+					continue
+				}
+				seen[studentID] = true
+				course.Enrollments = append(course.Enrollments, studentID)
+				instance.Registrations = append(instance.Registrations, Registration{StudentID: studentID, CourseID: courseID})
+			}
+			instance.Courses[courseID] = course
+			durations = append(durations, duration)
+
+		case "Periods":
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("line %d: expected a date, time and duration, got %q", line, text)
+			}
+			start, err := time.Parse("02:01:2006 15:04:05", fields[0]+" "+fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid period start: %v", line, err)
+			}
+			minutes, err := number(2)
+			if err != nil {
+				return nil, err
+			}
+			instance.Slots = append(instance.Slots, &Slot{Start: start, End: start.Add(time.Duration(minutes) * time.Minute)})
+
+		case "Rooms":
+			capacity, err := number(0)
+			if err != nil {
+				return nil, err
+			}
+			instance.Halls = append(instance.Halls, &Hall{ID: HallID(fmt.Sprintf("R%d", len(instance.Halls))), Capacity: capacity})
+
+		case "PeriodHardConstraints":
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("line %d: expected two exams and a constraint, got %q", line, text)
+			}
+			a, b := CourseID(fields[0]), CourseID(fields[2])
+			switch fields[1] {
+			case "EXAM_COINCIDENCE":
+				relations = append(relations, CourseRelation{A: a, Kind: RelationSameSlot, B: b})
+			case "EXCLUSION":
+				relations = append(relations, CourseRelation{A: a, Kind: RelationDifferentSlot, B: b})
+			case "AFTER":
+				// The first exam is after the second
+				relations = append(relations, CourseRelation{A: b, Kind: RelationPrecedes, B: a})
+			default:
+				return nil, fmt.Errorf("line %d: unknown period constraint %q", line, fields[1])
+			}
+
+		case "RoomHardConstraints":
+			// ROOM_EXCLUSIVE: halls are never shared between exams
+
+		case "InstitutionalWeightings":
+			var values []int
+			for i := 1; i < len(fields); i++ {
+				n, err := number(i)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, n)
+			}
+			instance.Weightings[fields[0]] = values
+
+		default:
+			return nil, fmt.Errorf("line %d: data outside a known section", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, r := range relations {
+		if instance.Courses[r.A] == nil || instance.Courses[r.B] == nil {
+			return nil, fmt.Errorf("period constraint between exams %s and %s refers to an unknown exam", r.A, r.B)
+		}
+	}
+	if len(relations) > 0 {
+		instance.Constraints = &Constraints{Relations: relations}
+	}
+
+	// Number the periods within their days, in the order of the file
+	days := make(map[string]int)
+	counts := make(map[string]int)
+	for _, slot := range instance.Slots {
+		date := slot.Start.Format("2006-01-02")
+		if _, ok := days[date]; !ok {
+			days[date] = len(days)
+		}
+		slot.DayIndex = days[date]
+		slot.IndexInDay = counts[date]
+		counts[date]++
+		slot.ID = slotIDFor(slot.Start, slot.IndexInDay)
+	}
+	sort.SliceStable(instance.Slots, func(i, j int) bool { return instance.Slots[i].Start.Before(instance.Slots[j].Start) })
+
+	for i, duration := range durations {
+		courseID := CourseID(strconv.Itoa(i))
+		allowed := make(map[SlotID]bool)
+		for _, slot := range instance.Slots {
+			if int(slot.End.Sub(slot.Start)/time.Minute) >= duration {
+				allowed[slot.ID] = true
+			}
+		}
+		if len(allowed) == len(instance.Slots) {
+			continue
+		}
+		if len(allowed) == 0 {
+			return nil, fmt.Errorf("exam %d lasts %d minutes, longer than every period", i, duration)
+		}
+		if instance.AllowedSlots == nil {
+			instance.AllowedSlots = make(map[CourseID]map[SlotID]bool)
+		}
+		instance.AllowedSlots[courseID] = allowed
+	}
+	return instance, nil
+}
+
+// CarterCost returns the proximity cost of a schedule as defined for the Toronto benchmarks:
+// for every student, each pair of exams d periods apart costs 2^(5-d) for d from 1 to 5, and
+// the total is divided by the number of students. The period of a slot is its index in slots.
+func CarterCost(assignments []*Assignment, courses map[CourseID]*Course, slots []*Slot) float64 {
+	period := make(map[SlotID]int, len(slots))
+	for i, s := range slots {
+		period[s.ID] = i
+	}
+
+	studentPeriods := make(map[StudentID][]int)
+	for _, a := range assignments {
+		p, ok := period[a.SlotID]
+		course := courses[a.CourseID]
+		if !ok || course == nil {
+			continue
+		}
+		for _, studentID := range course.Enrollments {
+			studentPeriods[studentID] = append(studentPeriods[studentID], p)
+		}
+	}
+
+	students := make(map[StudentID]bool)
+	for _, course := range courses {
+		for _, studentID := range course.Enrollments {
+			students[studentID] = true
+		}
+	}
+	if len(students) == 0 {
+		return 0
+	}
+
+	cost := 0
+	for _, periods := range studentPeriods {
+		for i := 0; i < len(periods); i++ {
+			for j := i + 1; j < len(periods); j++ {
+				d := periods[i] - periods[j]
+				if d < 0 {
+					d = -d
+				}
+				if d >= 1 && d <= 5 {
+					cost += 1 << (5 - d)
+				}
+			}
+		}
+	}
+	return float64(cost) / float64(len(students))
+}
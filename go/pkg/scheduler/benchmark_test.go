@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReadToronto(t *testing.T) {
+	crs := "0001 2\n0002 2\n0003 1\n"
+	stu := "0001 0002\n\n0001 0002 0003 \n"
+
+	instance, err := ReadToronto(strings.NewReader(crs), strings.NewReader(stu), 4, 3)
+	if err != nil {
+		t.Fatalf("ReadToronto failed: %v", err)
+	}
+	if len(instance.Courses) != 3 || len(instance.Registrations) != 5 {
+		t.Errorf("expected 5 registrations in 3 courses, got %d in %d", len(instance.Registrations), len(instance.Courses))
+	}
+	if got := instance.Courses["0003"].Enrollments; len(got) != 1 || got[0] != "2" {
+		t.Errorf("expected students to be numbered by non-blank line, got %v", got)
+	}
+	if len(instance.Slots) != 4 || instance.Slots[3].DayIndex != 1 || instance.Slots[2].IndexInDay != 2 {
+		t.Errorf("expected 4 periods over 2 days, got %+v", instance.Slots)
+	}
+
+	if _, err := ReadToronto(strings.NewReader(crs), strings.NewReader("0001 0004\n"), 4, 3); err == nil {
+		t.Error("expected an error for a course missing from the crs file")
+	}
+}
+
+func TestReadITC2007(t *testing.T) {
+	data := `[Exams:3]
+90, 1, 2, 3
+180, 2, 4
+60, 5
+[Periods:3]
+15:04:2005, 09:30:00, 180, 0
+15:04:2005, 14:00:00, 120, 0
+16:04:2005, 09:30:00, 180, 0
+[Rooms:2]
+260, 0
+30, 0
+[PeriodHardConstraints]
+0, AFTER, 2
+0, EXCLUSION, 1
+[RoomHardConstraints]
+1, ROOM_EXCLUSIVE
+[InstitutionalWeightings]
+TWOINAROW, 7
+FRONTLOAD, 100, 30, 5
+`
+	instance, err := ReadITC2007(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadITC2007 failed: %v", err)
+	}
+
+	if len(instance.Courses) != 3 || len(instance.Courses["0"].Enrollments) != 3 {
+		t.Errorf("unexpected courses: %v", instance.Courses)
+	}
+	if len(instance.Halls) != 2 || instance.Halls[1].ID != "R1" || instance.Halls[1].Capacity != 30 {
+		t.Errorf("unexpected halls: %+v", instance.Halls)
+	}
+	if len(instance.Slots) != 3 || instance.Slots[1].ID != "2005-04-15T14:00Z#2" || instance.Slots[2].DayIndex != 1 {
+		t.Errorf("unexpected slots: %+v", instance.Slots)
+	}
+	if allowed := instance.AllowedSlots["1"]; len(allowed) != 2 || allowed[instance.Slots[1].ID] {
+		t.Errorf("expected the 180 minute exam to be kept out of the 120 minute period, got %v", allowed)
+	}
+	if instance.AllowedSlots["0"] != nil {
+		t.Errorf("expected exam 0 to fit every period")
+	}
+
+	relations := instance.Constraints.Relations
+	if len(relations) != 2 || relations[0] != (CourseRelation{A: "2", Kind: RelationPrecedes, B: "0"}) {
+		t.Errorf("unexpected relations: %v", relations)
+	}
+	if w := instance.Weightings["FRONTLOAD"]; len(w) != 3 || w[0] != 100 {
+		t.Errorf("unexpected weightings: %v", instance.Weightings)
+	}
+
+	if _, err := ReadITC2007(strings.NewReader("[Exams:1]\n240, 1\n[Periods:1]\n15:04:2005, 09:30:00, 180, 0\n")); err == nil {
+		t.Error("expected an error for an exam longer than every period")
+	}
+}
+
+func TestCarterCost(t *testing.T) {
+	slots := make([]*Slot, 6)
+	for i := range slots {
+		slots[i] = &Slot{ID: SlotID(string(rune('a' + i)))}
+	}
+	courses := map[CourseID]*Course{
+		"c1": {ID: "c1", Enrollments: []StudentID{"s1", "s2"}},
+		"c2": {ID: "c2", Enrollments: []StudentID{"s1"}},
+		"c3": {ID: "c3", Enrollments: []StudentID{"s2"}},
+	}
+	assignments := []*Assignment{
+		{CourseID: "c1", SlotID: "a"},
+		{CourseID: "c2", SlotID: "b"}, // One period after c1: 16
+		{CourseID: "c3", SlotID: "d"}, // Three periods after c1: 4
+	}
+	if got := CarterCost(assignments, courses, slots); got != 10 {
+		t.Errorf("expected a cost of 10, got %v", got)
+	}
+}
+
+func TestToronto_SameSeedSameCost(t *testing.T) {
+	// Every course has the same size, so the colouring has many ties to break.
+	var crs, stu strings.Builder
+	for c := 1; c <= 20; c++ {
+		fmt.Fprintf(&crs, "%04d 6\n", c)
+	}
+	for s := 0; s < 40; s++ {
+		fmt.Fprintf(&stu, "%04d %04d %04d\n", s%20+1, (s+3)%20+1, (s+8)%20+1)
+	}
+
+	var penalty, cost float64
+	for i := 0; i < 5; i++ {
+		instance, err := ReadToronto(strings.NewReader(crs.String()), strings.NewReader(stu.String()), 10, 3)
+		if err != nil {
+			t.Fatalf("ReadToronto failed: %v", err)
+		}
+		config := PenaltyConfig{StudentProximityWeight: 1, MinGapViolationWeight: 10, CampusTravelWeight: 10}
+		result, err := RunSchedulingAttemptsWithConstraints(3, 1, instance.Courses, instance.Halls, instance.Slots, instance.AllowedSlots, NewConflictGraph(instance.Courses), 0, config, instance.Constraints)
+		if err != nil {
+			t.Fatalf("RunSchedulingAttempts failed: %v", err)
+		}
+		carter := CarterCost(result.Assignments, instance.Courses, instance.Slots)
+		if i > 0 && (result.Penalty != penalty || carter != cost) {
+			t.Fatalf("run %d gave penalty %v and Carter cost %v, the first run %v and %v", i+1, result.Penalty, carter, penalty, cost)
+		}
+		penalty, cost = result.Penalty, carter
+	}
+}
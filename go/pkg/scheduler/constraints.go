@@ -0,0 +1,278 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraints bundles the optional constraints that apply on top of student clashes and
+// per-course allowed slots. A nil *Constraints means there are none. Campus travel times are
+// soft: they add to the penalty and produce warnings rather than violations.
+type Constraints struct {
+	Students  map[StudentID]*StudentConstraint
+	Relations []CourseRelation
+	Forbidden []ForbiddenSlot
+	// Requirements lists the hall features each course needs.
+	Requirements map[CourseID][]string
+	// HallWindows limits when halls can be used.
+	HallWindows []HallWindow
+	// Travel holds travel times between campuses (hall groups).
+	Travel TravelTimes
+	// Aliases maps course codes to the shared exam they are sat in; see MergeAliases.
+	Aliases CourseAliases
+	// Sittings records the students of each sitting of split courses; see SplitSittings.
+	Sittings *SittingPlan
+}
+
+// RestrictAllowedSlots combines the allowed slots of each course with the constraints.
+// Forbidden slots of the course or its department, and slots in which an enrolled student is
+// unavailable, are removed from the course's allowed set; a course without an allowed set starts
+// from all slots. Courses left with no slot at all are reported as an error, since an empty set
+// would otherwise mean "all slots".
+func RestrictAllowedSlots(
+	allowedSlots map[CourseID]map[SlotID]bool,
+	courses map[CourseID]*Course,
+	slots []*Slot,
+	constraints *Constraints,
+) (map[CourseID]map[SlotID]bool, error) {
+	if constraints == nil {
+		return allowedSlots, nil
+	}
+
+	restricted := make(map[CourseID]map[SlotID]bool, len(allowedSlots))
+	for courseID, allowed := range allowedSlots {
+		restricted[courseID] = allowed
+	}
+
+	for courseID, course := range courses {
+		var forbidden []SlotPattern
+		for _, f := range constraints.Forbidden {
+			if f.Applies(courseID) {
+				forbidden = append(forbidden, f.Pattern)
+			}
+		}
+		var unavailable []SlotPattern
+		for _, studentID := range course.Enrollments {
+			if sc, ok := constraints.Students[studentID]; ok {
+				unavailable = append(unavailable, sc.Unavailable...)
+			}
+		}
+		if len(forbidden) == 0 && len(unavailable) == 0 {
+			continue
+		}
+
+		allowed := allowedSlots[courseID]
+		feasible := make(map[SlotID]bool)
+		for _, slot := range slots {
+			if len(allowed) > 0 && !allowed[slot.ID] {
+				continue
+			}
+			if !matchesAny(forbidden, slot) && !matchesAny(unavailable, slot) {
+				feasible[slot.ID] = true
+			}
+		}
+		if len(feasible) == 0 {
+			return nil, fmt.Errorf("course %s has no feasible slot: every allowed slot is forbidden or clashes with a student's unavailability", courseID)
+		}
+		restricted[courseID] = feasible
+	}
+
+	return restricted, nil
+}
+
+// AccommodatedCount returns how many students enrolled in the course need a designated room.
+func (c *Constraints) AccommodatedCount(course *Course) int {
+	if c == nil {
+		return 0
+	}
+	seen := make(map[StudentID]bool)
+	for _, studentID := range course.Enrollments {
+		if sc, ok := c.Students[studentID]; ok && sc.NeedsDesignatedRoom() {
+			seen[studentID] = true
+		}
+	}
+	return len(seen)
+}
+
+// RequiredFeatures returns the hall features the course needs.
+func (c *Constraints) RequiredFeatures(courseID CourseID) []string {
+	if c == nil {
+		return nil
+	}
+	return c.Requirements[courseID]
+}
+
+// travel returns the campus travel times, or nil if there are no constraints.
+func (c *Constraints) travel() TravelTimes {
+	if c == nil {
+		return nil
+	}
+	return c.Travel
+}
+
+// aliases returns the course aliases, or nil if there are no constraints.
+func (c *Constraints) aliases() CourseAliases {
+	if c == nil {
+		return nil
+	}
+	return c.Aliases
+}
+
+// relations returns the course relations, or nil if there are no constraints.
+func (c *Constraints) relations() []CourseRelation {
+	if c == nil {
+		return nil
+	}
+	return c.Relations
+}
+
+// checkConstraints adds a violation to the report for every broken constraint.
+func checkConstraints(
+	report *ValidationReport,
+	registrations []Registration,
+	assignments []*Assignment,
+	halls []*Hall,
+	slots []*Slot,
+	constraints *Constraints,
+) {
+	if constraints == nil {
+		return
+	}
+
+	slotMap := make(map[SlotID]*Slot, len(slots))
+	for _, s := range slots {
+		slotMap[s.ID] = s
+	}
+	hallMap := make(map[HallID]*Hall, len(halls))
+	for _, h := range halls {
+		hallMap[h.ID] = h
+	}
+	assignmentMap := make(map[CourseID]*Assignment, len(assignments))
+	for _, a := range assignments {
+		assignmentMap[a.CourseID] = a
+	}
+
+	// --- Student availability and accommodations ---
+	accommodated := make(map[CourseID]map[StudentID]bool)
+	for _, reg := range registrations {
+		sc, ok := constraints.Students[reg.StudentID]
+		if !ok {
+			continue
+		}
+		assignment, ok := assignmentMap[reg.CourseID]
+		if !ok {
+			continue
+		}
+		if slot, ok := slotMap[assignment.SlotID]; ok && matchesAny(sc.Unavailable, slot) {
+			report.ConstraintViolations = append(report.ConstraintViolations,
+				fmt.Sprintf("student %s is unavailable in slot %s but sits course %s", reg.StudentID, assignment.SlotID, reg.CourseID))
+		}
+		if sc.NeedsDesignatedRoom() {
+			if accommodated[reg.CourseID] == nil {
+				accommodated[reg.CourseID] = make(map[StudentID]bool)
+			}
+			accommodated[reg.CourseID][reg.StudentID] = true
+		}
+	}
+
+	for _, a := range assignments {
+		needed := len(accommodated[a.CourseID])
+		if needed == 0 {
+			continue
+		}
+		designated := 0
+		for _, hallID := range strings.Split(a.Halls, ";") {
+			if h, ok := hallMap[HallID(hallID)]; ok && h.Accommodation {
+				designated += h.Capacity
+			}
+		}
+		if designated < needed {
+			report.ConstraintViolations = append(report.ConstraintViolations,
+				fmt.Sprintf("course %s has %d students needing a designated room but only %d designated seats in halls [%s]", a.CourseID, needed, designated, a.Halls))
+		}
+	}
+
+	// --- Hall features ---
+	for _, a := range assignments {
+		required := constraints.Requirements[a.CourseID]
+		if len(required) == 0 || a.Halls == "" {
+			continue
+		}
+		for _, hallID := range strings.Split(a.Halls, ";") {
+			if h, ok := hallMap[HallID(hallID)]; ok && !h.HasFeatures(required) {
+				report.ConstraintViolations = append(report.ConstraintViolations,
+					fmt.Sprintf("course %s requires features [%s] but hall %s does not have them all", a.CourseID, strings.Join(required, ";"), hallID))
+			}
+		}
+	}
+
+	// --- Hall availability ---
+	if len(constraints.HallWindows) > 0 {
+		for _, a := range assignments {
+			slot, ok := slotMap[a.SlotID]
+			if !ok || a.Halls == "" {
+				continue
+			}
+			for _, hallID := range strings.Split(a.Halls, ";") {
+				if !constraints.HallAvailable(HallID(hallID), slot) {
+					report.ConstraintViolations = append(report.ConstraintViolations,
+						fmt.Sprintf("course %s uses hall %s in slot %s, outside the hall's availability", a.CourseID, hallID, a.SlotID))
+				}
+			}
+		}
+	}
+
+	// --- Forbidden slots ---
+	for _, a := range assignments {
+		slot, ok := slotMap[a.SlotID]
+		if !ok {
+			continue
+		}
+		for _, f := range constraints.Forbidden {
+			if f.Applies(a.CourseID) && f.Pattern.Matches(slot) {
+				report.ConstraintViolations = append(report.ConstraintViolations,
+					fmt.Sprintf("course %s is in forbidden slot %s (%s)", a.CourseID, a.SlotID, f))
+			}
+		}
+	}
+
+	// --- Course relations ---
+	for _, r := range constraints.Relations {
+		a, okA := assignmentMap[r.A]
+		b, okB := assignmentMap[r.B]
+		if !okA || !okB {
+			continue
+		}
+		slotA, okA := slotMap[a.SlotID]
+		slotB, okB := slotMap[b.SlotID]
+		if !okA || !okB {
+			continue
+		}
+		if !r.Satisfied(slotA, slotB) {
+			report.ConstraintViolations = append(report.ConstraintViolations,
+				fmt.Sprintf("relation violated: %s (slots %s and %s)", r, a.SlotID, b.SlotID))
+		}
+	}
+
+	// --- Campus travel ---
+	if len(constraints.Travel) > 0 {
+		courses := coursesFromRegistrations(registrations)
+		report.TravelWarnings, _ = campusTravel(assignments, courses, halls, slots, constraints.Travel)
+	}
+
+	if len(report.ConstraintViolations) > 0 {
+		report.Valid = false
+	}
+}
+
+// coursesFromRegistrations groups registrations into courses.
+func coursesFromRegistrations(registrations []Registration) map[CourseID]*Course {
+	courses := make(map[CourseID]*Course)
+	for _, reg := range registrations {
+		if courses[reg.CourseID] == nil {
+			courses[reg.CourseID] = &Course{ID: reg.CourseID}
+		}
+		courses[reg.CourseID].Enrollments = append(courses[reg.CourseID].Enrollments, reg.StudentID)
+	}
+	return courses
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Severity is how serious an input problem is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // The row could not be used and was skipped
+	SeverityWarning Severity = "warning" // The row looks wrong or repeats another row; the data is still usable
+)
+
+// Diagnostic describes a skipped or suspicious row of an input file.
+type Diagnostic struct {
+	File     string   `json:"file"`             // Input name, e.g. "registrations" or "halls"
+	Line     int      `json:"line"`             // 1-based line number in the file
+	Column   string   `json:"column,omitempty"` // Column name, if the problem is in one field
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// String formats the diagnostic as "file:line: column: severity: message".
+func (d Diagnostic) String() string {
+	if d.Column != "" {
+		return fmt.Sprintf("%s:%d: %s: %s: %s", d.File, d.Line, d.Column, d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", d.File, d.Line, d.Severity, d.Message)
+}
+
+// diagnostics collects the diagnostics of one input file.
+type diagnostics struct {
+	file  string
+	items []Diagnostic
+}
+
+func (d *diagnostics) add(severity Severity, line int, column, format string, args ...any) {
+	d.items = append(d.items, Diagnostic{
+		File:     d.file,
+		Line:     line,
+		Column:   column,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// readError records a CSV syntax error, with its line if it has one.
+func (d *diagnostics) readError(err error) {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		d.add(SeverityError, parseErr.Line, "", "%v", parseErr.Err)
+		return
+	}
+	d.add(SeverityError, 0, "", "%v", err)
+}
+
+// strictError returns an error summarising the error-level diagnostics, or nil if there are none.
+func (d *diagnostics) strictError() error {
+	var first *Diagnostic
+	count := 0
+	for i := range d.items {
+		if d.items[i].Severity == SeverityError {
+			if first == nil {
+				first = &d.items[i]
+			}
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	if count == 1 {
+		return fmt.Errorf("invalid %s input: %s", d.file, first)
+	}
+	return fmt.Errorf("invalid %s input: %d rows skipped, first at %s", d.file, count, first)
+}
+
+// idVariants remembers the IDs seen so far by their normalised form, to spot IDs that differ
+// only in case or spacing, such as "CS101", "cs101" and "CS 101".
+type idVariants map[string]string
+
+// check warns if id is a variant of an ID seen before.
+func (v idVariants) check(d *diagnostics, line int, column, kind, id string) {
+	key := normalizeID(id)
+	first, ok := v[key]
+	if !ok {
+		v[key] = id
+		return
+	}
+	if first != id {
+		d.add(SeverityWarning, line, column, "%s ID %q differs from %q only in case or spacing", kind, id, first)
+	}
+}
+
+// normalizeID folds case and drops spaces, so that variants of an ID compare equal.
+func normalizeID(id string) string {
+	return strings.ToLower(strings.Join(strings.Fields(id), ""))
+}
+
+// recordLine returns the line of the record most recently read by r.
+func recordLine(r *csv.Reader) int {
+	line, _ := r.FieldPos(0)
+	return line
+}
@@ -0,0 +1,188 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeKind classifies a course change between two schedules.
+type ChangeKind string
+
+const (
+	ChangeAdded       ChangeKind = "added"       // Course only appears in the new schedule
+	ChangeRemoved     ChangeKind = "removed"     // Course only appears in the old schedule
+	ChangeSlotChanged ChangeKind = "slotChanged" // Course moved to another slot (halls may also differ)
+	ChangeHallChanged ChangeKind = "hallChanged" // Course stayed in its slot but changed halls
+)
+
+// CourseChange describes how a course differs between two versions of a schedule.
+// An empty FromSlot means the course is new; an empty ToSlot means it was dropped.
+type CourseChange struct {
+	CourseID         CourseID    `json:"courseId"`
+	Kind             ChangeKind  `json:"kind"`
+	FromSlot         SlotID      `json:"fromSlot,omitempty"`
+	ToSlot           SlotID      `json:"toSlot,omitempty"`
+	FromHalls        string      `json:"fromHalls,omitempty"`
+	ToHalls          string      `json:"toHalls,omitempty"`
+	AffectedStudents []StudentID `json:"affectedStudents"`
+}
+
+// ScheduleDiff lists every course that changed between two schedules.
+type ScheduleDiff struct {
+	Changes          []CourseChange `json:"changes"`
+	AffectedStudents []StudentID    `json:"affectedStudents"` // Union of students affected by any change
+}
+
+// DiffSchedules compares two schedules course by course.
+// Affected students are taken from courses, which is usually parsed from the newer registrations;
+// courses missing from it are reported with no affected students. Use
+// DiffSchedulesWithPrevious to also count the students of the older registrations.
+func DiffSchedules(before, after []*Assignment, courses map[CourseID]*Course) *ScheduleDiff {
+	return DiffSchedulesWithPrevious(before, after, nil, courses)
+}
+
+// DiffSchedulesWithPrevious runs DiffSchedules with the courses of the registrations the older
+// schedule was made for, which may be nil. A removed course then affects its previous students
+// and a moved course both its previous and its current students.
+func DiffSchedulesWithPrevious(before, after []*Assignment, previous, courses map[CourseID]*Course) *ScheduleDiff {
+	afterMap := make(map[CourseID]*Assignment, len(after))
+	for _, a := range after {
+		afterMap[a.CourseID] = a
+	}
+
+	studentsOf := func(courseID CourseID, from ...map[CourseID]*Course) []StudentID {
+		var students []StudentID
+		for _, m := range from {
+			if course, ok := m[courseID]; ok {
+				students = append(students, course.Enrollments...)
+			}
+		}
+		return sortedStudents(students)
+	}
+	if previous == nil {
+		previous = courses
+	}
+
+	diff := &ScheduleDiff{Changes: []CourseChange{}}
+	seen := make(map[CourseID]bool, len(before))
+	for _, b := range before {
+		seen[b.CourseID] = true
+		a, ok := afterMap[b.CourseID]
+		if !ok {
+			diff.Changes = append(diff.Changes, CourseChange{
+				CourseID:         b.CourseID,
+				Kind:             ChangeRemoved,
+				FromSlot:         b.SlotID,
+				FromHalls:        b.Halls,
+				AffectedStudents: studentsOf(b.CourseID, previous),
+			})
+			continue
+		}
+
+		kind := ChangeKind("")
+		if a.SlotID != b.SlotID {
+			kind = ChangeSlotChanged
+		} else if normalizeHalls(a.Halls) != normalizeHalls(b.Halls) {
+			kind = ChangeHallChanged
+		}
+		if kind == "" {
+			continue
+		}
+		diff.Changes = append(diff.Changes, CourseChange{
+			CourseID:         b.CourseID,
+			Kind:             kind,
+			FromSlot:         b.SlotID,
+			ToSlot:           a.SlotID,
+			FromHalls:        b.Halls,
+			ToHalls:          a.Halls,
+			AffectedStudents: studentsOf(b.CourseID, previous, courses),
+		})
+	}
+	for _, a := range after {
+		if !seen[a.CourseID] {
+			diff.Changes = append(diff.Changes, CourseChange{
+				CourseID:         a.CourseID,
+				Kind:             ChangeAdded,
+				ToSlot:           a.SlotID,
+				ToHalls:          a.Halls,
+				AffectedStudents: studentsOf(a.CourseID, courses),
+			})
+		}
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool { return diff.Changes[i].CourseID < diff.Changes[j].CourseID })
+
+	var affected []StudentID
+	for _, c := range diff.Changes {
+		affected = append(affected, c.AffectedStudents...)
+	}
+	diff.AffectedStudents = sortedStudents(affected)
+
+	return diff
+}
+
+// DiffScheduleCSVs parses two schedule CSVs and compares them with DiffSchedules.
+func DiffScheduleCSVs(beforeCSV, afterCSV string, courses map[CourseID]*Course) (*ScheduleDiff, error) {
+	return DiffScheduleCSVsWithPrevious(beforeCSV, afterCSV, nil, courses)
+}
+
+// DiffScheduleCSVsWithPrevious parses two schedule CSVs and compares them with DiffSchedulesWithPrevious.
+func DiffScheduleCSVsWithPrevious(beforeCSV, afterCSV string, previous, courses map[CourseID]*Course) (*ScheduleDiff, error) {
+	before, err := parseScheduleCSV(beforeCSV)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing old schedule CSV: %w", err)
+	}
+	after, err := parseScheduleCSV(afterCSV)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing new schedule CSV: %w", err)
+	}
+	return DiffSchedulesWithPrevious(before, after, previous, courses), nil
+}
+
+// FormatDiff renders a diff as human-readable text, one line per change.
+func FormatDiff(diff *ScheduleDiff) string {
+	if len(diff.Changes) == 0 {
+		return "No changes.\n"
+	}
+
+	var sb strings.Builder
+	for _, c := range diff.Changes {
+		switch c.Kind {
+		case ChangeAdded:
+			fmt.Fprintf(&sb, "+ %s added in slot %s [%s]", c.CourseID, c.ToSlot, c.ToHalls)
+		case ChangeRemoved:
+			fmt.Fprintf(&sb, "- %s removed from slot %s [%s]", c.CourseID, c.FromSlot, c.FromHalls)
+		case ChangeSlotChanged:
+			fmt.Fprintf(&sb, "~ %s moved from slot %s [%s] to slot %s [%s]", c.CourseID, c.FromSlot, c.FromHalls, c.ToSlot, c.ToHalls)
+		case ChangeHallChanged:
+			fmt.Fprintf(&sb, "~ %s changed halls in slot %s: [%s] -> [%s]", c.CourseID, c.ToSlot, c.FromHalls, c.ToHalls)
+		}
+		fmt.Fprintf(&sb, " (%d students affected)\n", len(c.AffectedStudents))
+	}
+	fmt.Fprintf(&sb, "%d changes, %d students affected in total.\n", len(diff.Changes), len(diff.AffectedStudents))
+	return sb.String()
+}
+
+// normalizeHalls returns the semicolon-separated hall list in sorted order.
+func normalizeHalls(halls string) string {
+	if halls == "" {
+		return ""
+	}
+	parts := strings.Split(halls, ";")
+	sort.Strings(parts)
+	return strings.Join(parts, ";")
+}
+
+// sortedStudents returns a sorted, de-duplicated copy of the given student IDs.
+func sortedStudents(ids []StudentID) []StudentID {
+	seen := make(map[StudentID]bool, len(ids))
+	out := make([]StudentID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffScheduleCSVs(t *testing.T) {
+	before := `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+c1,slot1,2025-01-06T09:00:00Z,H1,2,
+c2,slot2,2025-01-06T14:00:00Z,H1;H2,2,
+c3,slot2,2025-01-06T14:00:00Z,H3,1,
+c4,slot1,2025-01-06T09:00:00Z,H2,1,
+`
+	after := `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+c1,slot1,2025-01-06T09:00:00Z,H1,2,
+c2,slot2,2025-01-06T14:00:00Z,H2;H1,2,
+c3,slot1,2025-01-06T09:00:00Z,H3,1,
+c4,slot1,2025-01-06T09:00:00Z,H4,1,
+c5,slot2,2025-01-06T14:00:00Z,H3,1,
+`
+	courses, _, _ := ParseRegistrations(`student_id,course_id
+s1,c1
+s2,c1
+s1,c2
+s2,c2
+s3,c3
+s4,c4
+s3,c5
+`, nil)
+
+	diff, err := DiffScheduleCSVs(before, after, courses)
+	if err != nil {
+		t.Fatalf("DiffScheduleCSVs failed: %v", err)
+	}
+
+	// c1 is unchanged and c2 only lists its halls in a different order.
+	expected := map[CourseID]ChangeKind{
+		"c3": ChangeSlotChanged,
+		"c4": ChangeHallChanged,
+		"c5": ChangeAdded,
+	}
+	if len(diff.Changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %+v", len(expected), diff.Changes)
+	}
+	for _, c := range diff.Changes {
+		if expected[c.CourseID] != c.Kind {
+			t.Errorf("expected %s to be %q, got %q", c.CourseID, expected[c.CourseID], c.Kind)
+		}
+	}
+
+	// s3 is affected by both c3 and c5 but counted once.
+	if len(diff.AffectedStudents) != 2 {
+		t.Errorf("expected 2 affected students, got %v", diff.AffectedStudents)
+	}
+}
+
+func TestDiffSchedules_Removed(t *testing.T) {
+	before := []*Assignment{{CourseID: "c1", SlotID: "slot1", Halls: "H1"}}
+	diff := DiffSchedules(before, nil, map[CourseID]*Course{})
+
+	if len(diff.Changes) != 1 || diff.Changes[0].Kind != ChangeRemoved {
+		t.Fatalf("expected c1 to be removed, got %+v", diff.Changes)
+	}
+	if !strings.Contains(FormatDiff(diff), "c1 removed from slot slot1") {
+		t.Errorf("unexpected text output:\n%s", FormatDiff(diff))
+	}
+}
+
+func TestDiffSchedulesWithPrevious(t *testing.T) {
+	before := []*Assignment{
+		{CourseID: "c1", SlotID: "slot1", Halls: "H1"},
+		{CourseID: "c2", SlotID: "slot1", Halls: "H2"},
+	}
+	after := []*Assignment{{CourseID: "c2", SlotID: "slot2", Halls: "H2"}}
+	previous := map[CourseID]*Course{
+		"c1": {ID: "c1", Enrollments: []StudentID{"s1", "s2"}},
+		"c2": {ID: "c2", Enrollments: []StudentID{"s3", "s4"}},
+	}
+	courses := map[CourseID]*Course{"c2": {ID: "c2", Enrollments: []StudentID{"s4", "s5"}}}
+
+	diff := DiffSchedulesWithPrevious(before, after, previous, courses)
+	if len(diff.Changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", diff.Changes)
+	}
+	// c1 was dropped from the new registrations, so its previous students are affected
+	if c := diff.Changes[0]; c.Kind != ChangeRemoved || !reflect.DeepEqual(c.AffectedStudents, []StudentID{"s1", "s2"}) {
+		t.Errorf("expected the removed c1 to affect s1 and s2, got %+v", c)
+	}
+	// s3 dropped c2 and s5 joined it; both have to know it moved
+	if c := diff.Changes[1]; c.Kind != ChangeSlotChanged || !reflect.DeepEqual(c.AffectedStudents, []StudentID{"s3", "s4", "s5"}) {
+		t.Errorf("expected the moved c2 to affect s3, s4 and s5, got %+v", c)
+	}
+	if len(diff.AffectedStudents) != 5 {
+		t.Errorf("expected 5 affected students, got %v", diff.AffectedStudents)
+	}
+
+	if diff := DiffSchedules(before, after, courses); len(diff.Changes[0].AffectedStudents) != 0 {
+		t.Errorf("expected no affected students for c1 without the previous registrations, got %v", diff.Changes[0].AffectedStudents)
+	}
+}
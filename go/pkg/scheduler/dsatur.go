@@ -9,6 +9,15 @@ import (
 // DSATUR assigns colors (slots) to courses using the DSATUR algorithm.
 // It returns a mapping of CourseID to SlotID, or an error if no solution is found.
 func DSATUR(graph *ConflictGraph, slots []*Slot, allowedSlots map[CourseID]map[SlotID]bool, seed int64) (map[CourseID]int, error) {
+	return DSATURWithRelations(graph, slots, allowedSlots, nil, seed)
+}
+
+// DSATURWithRelations runs DSATUR with course relations as hard constraints; courses linked by
+// same_slot relations are colored together as one merged node. A course is only offered slots that
+// leave its related courses a slot satisfying the relation, so the order in which courses are
+// colored does not rule out precedes or same_day. Relations naming courses outside the graph are
+// ignored.
+func DSATURWithRelations(graph *ConflictGraph, slots []*Slot, allowedSlots map[CourseID]map[SlotID]bool, relations []CourseRelation, seed int64) (map[CourseID]int, error) {
 	numCourses := len(graph.Courses)
 	numSlots := len(slots)
 	coloring := make(map[CourseID]int) // Maps CourseID to slot index
@@ -36,6 +45,35 @@ func DSATUR(graph *ConflictGraph, slots []*Slot, allowedSlots map[CourseID]map[S
 		}
 	}
 
+	// Merge same-slot groups into one node; members that conflict can never share a slot
+	groups := make(map[int][]int)
+	for courseID, group := range sameSlotGroups(relations) {
+		i, ok := graph.CourseIndex[courseID]
+		if !ok {
+			continue
+		}
+		for _, other := range group {
+			j, ok := graph.CourseIndex[other]
+			if !ok {
+				continue
+			}
+			if graph.AdjMatrix[i][j] > 0 {
+				return nil, fmt.Errorf("infeasible schedule: courses %s and %s must share a slot but have common students", courseID, other)
+			}
+			groups[i] = append(groups[i], j)
+		}
+	}
+	if courseID, empty := narrowSlots(relations, graph, slots, availableSlots); empty {
+		return nil, fmt.Errorf("infeasible schedule: no slot for course %s satisfies its course relations", courseID)
+	}
+	relIndex := newRelationIndex(relations)
+	placed := func(courseID CourseID) (*Slot, bool) {
+		if slotIdx, colored := coloring[courseID]; colored {
+			return slots[slotIdx], true
+		}
+		return nil, false
+	}
+
 	// PRNG for tie-breaking
 	rng := rand.New(rand.NewSource(seed))
 
@@ -86,17 +124,31 @@ func DSATUR(graph *ConflictGraph, slots []*Slot, allowedSlots map[CourseID]map[S
 		}
 		sort.Ints(possibleSlots)
 
+		members := []int{nextCourseIdx}
+		if group, ok := groups[nextCourseIdx]; ok {
+			members = group
+		}
+
 		assignedSlot := -1
 		for _, slotIdx := range possibleSlots {
-			// Check if this slot is available
+			// Check if this slot is available for every member of the node
 			isAvailable := true
-			for neighborIdx, weight := range graph.AdjMatrix[nextCourseIdx] {
-				if weight > 0 {
-					if neighborSlot, colored := coloring[graph.Courses[neighborIdx]]; colored && neighborSlot == slotIdx {
-						isAvailable = false
-						break
+			for _, m := range members {
+				if !availableSlots[m][slotIdx] || !relIndex.allows(graph.Courses[m], slots[slotIdx], placed) {
+					isAvailable = false
+					break
+				}
+				for neighborIdx, weight := range graph.AdjMatrix[m] {
+					if weight > 0 {
+						if neighborSlot, colored := coloring[graph.Courses[neighborIdx]]; colored && neighborSlot == slotIdx {
+							isAvailable = false
+							break
+						}
 					}
 				}
+				if !isAvailable {
+					break
+				}
 			}
 			if isAvailable {
 				assignedSlot = slotIdx
@@ -108,26 +160,39 @@ func DSATUR(graph *ConflictGraph, slots []*Slot, allowedSlots map[CourseID]map[S
 			return nil, fmt.Errorf("infeasible schedule: cannot assign a slot to course %s", courseID)
 		}
 
-		coloring[courseID] = assignedSlot
+		for _, m := range members {
+			coloring[graph.Courses[m]] = assignedSlot
+		}
+		if len(relIndex) > 0 {
+			// Keep only the slots that still suit the related courses
+			for _, m := range members {
+				availableSlots[m] = map[int]bool{assignedSlot: true}
+			}
+			if other, empty := narrowSlots(relations, graph, slots, availableSlots); empty {
+				return nil, fmt.Errorf("infeasible schedule: no slot left for course %s after placing %s", other, courseID)
+			}
+		}
 
 		// Update saturation degrees of neighbors
-		for neighborIdx, weight := range graph.AdjMatrix[nextCourseIdx] {
-			if weight > 0 {
-				if _, colored := coloring[graph.Courses[neighborIdx]]; !colored {
-					// If neighbor had this slot as an option, its saturation increases
-					if availableSlots[neighborIdx][assignedSlot] {
-						// To be precise, we should check if this color was not already forbidden by another neighbor
-						isNewForbiddenColor := true
-						for otherNeighborIdx, otherWeight := range graph.AdjMatrix[neighborIdx] {
-							if otherWeight > 0 {
-								if otherSlot, colored := coloring[graph.Courses[otherNeighborIdx]]; colored && otherSlot == assignedSlot {
-									isNewForbiddenColor = false
-									break
+		for _, m := range members {
+			for neighborIdx, weight := range graph.AdjMatrix[m] {
+				if weight > 0 {
+					if _, colored := coloring[graph.Courses[neighborIdx]]; !colored {
+						// If neighbor had this slot as an option, its saturation increases
+						if availableSlots[neighborIdx][assignedSlot] {
+							// To be precise, we should check if this color was not already forbidden by another neighbor
+							isNewForbiddenColor := true
+							for otherNeighborIdx, otherWeight := range graph.AdjMatrix[neighborIdx] {
+								if otherWeight > 0 {
+									if otherSlot, colored := coloring[graph.Courses[otherNeighborIdx]]; colored && otherSlot == assignedSlot {
+										isNewForbiddenColor = false
+										break
+									}
 								}
 							}
-						}
-						if isNewForbiddenColor {
-							saturation[neighborIdx]++
+							if isNewForbiddenColor {
+								saturation[neighborIdx]++
+							}
 						}
 					}
 				}
@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"sort"
+	"strings"
+)
+
+// ParseCourseRequirements parses the course requirements CSV data.
+// Required columns are course_id and features (semicolon-separated, e.g. "lab;accessible").
+// Feature names are case-insensitive. A course may appear on several rows, which are merged.
+// Rows that cannot be used are skipped; see ParseCourseRequirementsWithDiagnostics to find out which.
+func ParseCourseRequirements(csvData string) (map[CourseID][]string, error) {
+	requirements, _, err := ParseCourseRequirementsWithDiagnostics(csvData, false)
+	return requirements, err
+}
+
+// ParseCourseRequirementsWithDiagnostics parses the course requirements CSV data like
+// ParseCourseRequirements and also reports every skipped row. In strict mode any skipped row is an error.
+func ParseCourseRequirementsWithDiagnostics(csvData string, strict bool) (map[CourseID][]string, []Diagnostic, error) {
+	requirements := make(map[CourseID][]string)
+	if csvData == "" {
+		return requirements, nil, nil
+	}
+
+	table, err := newCSVTable("course requirements", csvData, "course_id", "features")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for table.next() {
+		courseID := CourseID(table.field("course_id"))
+		if courseID == "" {
+			table.skip("course_id", "empty course ID")
+			continue
+		}
+		requirements[courseID] = mergeFeatures(requirements[courseID], parseFeatures(table.field("features")))
+	}
+
+	diagnostics, err := table.result(strict)
+	if err != nil {
+		return nil, diagnostics, err
+	}
+	return requirements, diagnostics, nil
+}
+
+// HasFeatures reports whether the hall offers every one of the required features.
+func (h *Hall) HasFeatures(required []string) bool {
+	for _, feature := range required {
+		if !containsString(h.Features, feature) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseFeatures splits a semicolon-separated feature list into lower-case feature names.
+func parseFeatures(s string) []string {
+	var features []string
+	for _, f := range splitList(s) {
+		features = mergeFeatures(features, []string{strings.ToLower(f)})
+	}
+	return features
+}
+
+// mergeFeatures adds the features that are not yet in the list and keeps it sorted.
+func mergeFeatures(features, more []string) []string {
+	for _, f := range more {
+		if !containsString(features, f) {
+			features = append(features, f)
+		}
+	}
+	sort.Strings(features)
+	return features
+}
+
+func containsString(features []string, feature string) bool {
+	for _, f := range features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
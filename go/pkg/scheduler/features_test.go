@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"testing"
+)
+
+func TestParseCourseRequirements(t *testing.T) {
+	requirements, err := ParseCourseRequirements(`course_id,features
+CS101,Lab
+CS101,accessible;lab
+ART2,drawing
+`)
+	if err != nil {
+		t.Fatalf("ParseCourseRequirements failed: %v", err)
+	}
+
+	if got := requirements["CS101"]; len(got) != 2 || got[0] != "accessible" || got[1] != "lab" {
+		t.Errorf("unexpected requirements for CS101: %v", got)
+	}
+	if got := requirements["ART2"]; len(got) != 1 || got[0] != "drawing" {
+		t.Errorf("unexpected requirements for ART2: %v", got)
+	}
+
+	if _, err := ParseCourseRequirements("course_id\nCS101\n"); err == nil {
+		t.Error("expected an error for a missing features column")
+	}
+}
+
+func TestHall_HasFeatures(t *testing.T) {
+	hall := &Hall{ID: "Lab1", Features: []string{"accessible", "lab"}}
+	if !hall.HasFeatures(nil) {
+		t.Error("every hall should satisfy an empty requirement")
+	}
+	if !hall.HasFeatures([]string{"lab", "accessible"}) {
+		t.Error("expected Lab1 to have lab and accessible")
+	}
+	if hall.HasFeatures([]string{"lab", "drawing"}) {
+		t.Error("Lab1 should not have drawing")
+	}
+}
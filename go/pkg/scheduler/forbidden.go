@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ForbiddenSlot rules out the slots matched by a pattern for one course or a whole department.
+// Exactly one of CourseID and Department is set.
+type ForbiddenSlot struct {
+	CourseID   CourseID
+	Department string
+	Pattern    SlotPattern
+}
+
+// Applies reports whether the rule covers the course. Departments are derived with CourseDepartment.
+func (f ForbiddenSlot) Applies(courseID CourseID) bool {
+	if f.CourseID != "" {
+		return f.CourseID == courseID
+	}
+	return strings.EqualFold(f.Department, CourseDepartment(courseID))
+}
+
+// String describes the rule for reports.
+func (f ForbiddenSlot) String() string {
+	if f.CourseID != "" {
+		return fmt.Sprintf("course %s not in %q", f.CourseID, f.Pattern)
+	}
+	return fmt.Sprintf("department %s not in %q", f.Department, f.Pattern)
+}
+
+// ParseForbiddenSlots parses the forbidden slots CSV data.
+// Columns are course_id, department and slot; each row needs a slot pattern and either a
+// course ID or a department. The slot column accepts anything ParseSlotPattern does.
+// Rows that cannot be used are skipped; see ParseForbiddenSlotsWithDiagnostics to find out which.
+func ParseForbiddenSlots(csvData string) ([]ForbiddenSlot, error) {
+	rules, _, err := ParseForbiddenSlotsWithDiagnostics(csvData, false)
+	return rules, err
+}
+
+// ParseForbiddenSlotsWithDiagnostics parses the forbidden slots CSV data like ParseForbiddenSlots
+// and also reports every skipped row. In strict mode any skipped row is an error.
+func ParseForbiddenSlotsWithDiagnostics(csvData string, strict bool) ([]ForbiddenSlot, []Diagnostic, error) {
+	if csvData == "" {
+		return nil, nil, nil
+	}
+
+	table, err := newCSVTable("forbidden slots", csvData, "slot")
+	if err != nil {
+		return nil, nil, err
+	}
+	if !table.has("course_id") && !table.has("department") {
+		return nil, nil, fmt.Errorf("missing required columns: slot and course_id or department")
+	}
+
+	var rules []ForbiddenSlot
+	for table.next() {
+		rule := ForbiddenSlot{CourseID: CourseID(table.field("course_id"))}
+		if rule.CourseID == "" {
+			rule.Department = table.field("department")
+		}
+		if rule.CourseID == "" && rule.Department == "" {
+			table.skip("", "no course ID or department")
+			continue
+		}
+
+		rule.Pattern, err = ParseSlotPattern(table.field("slot"))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid forbidden slot for %s%s: %w", rule.CourseID, rule.Department, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	diagnostics, err := table.result(strict)
+	if err != nil {
+		return nil, diagnostics, err
+	}
+	return rules, diagnostics, nil
+}
+
+// CheckForbiddenSlots reports forbidden slots CSV rows whose slot pattern matches none of the slots,
+// which usually means a typo. Such rows rule nothing out, so they are warnings.
+func CheckForbiddenSlots(csvData string, slots []*Slot) []Diagnostic {
+	return checkSlotPatterns("forbidden slots", csvData, "slot", slots)
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParseForbiddenSlots(t *testing.T) {
+	rules, err := ParseForbiddenSlots(`course_id,department,slot
+CS101,,Fri afternoon
+,MATH,2025-01-07
+,,2025-01-08
+`)
+	if err != nil {
+		t.Fatalf("ParseForbiddenSlots failed: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+	if !rules[0].Applies("CS101") || rules[0].Applies("CS102") {
+		t.Errorf("course rule applies to the wrong courses: %+v", rules[0])
+	}
+	if !rules[1].Applies("MATH201") || rules[1].Applies("CS101") {
+		t.Errorf("department rule applies to the wrong courses: %+v", rules[1])
+	}
+}
+
+func TestParseForbiddenSlots_HeaderErrors(t *testing.T) {
+	// A file with no header row reports the read error, not a missing column.
+	if _, err := ParseForbiddenSlots("# no rows\n"); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF for a file without a header, got %v", err)
+	}
+	if _, err := ParseForbiddenSlots("course_id,date\nCS101,Fri\n"); err == nil || !strings.Contains(err.Error(), "slot") {
+		t.Errorf("expected an error naming the slot column, got %v", err)
+	}
+	if _, err := ParseForbiddenSlots("room,slot\nH1,Fri\n"); err == nil || !strings.Contains(err.Error(), "course_id or department") {
+		t.Errorf("expected an error naming course_id or department, got %v", err)
+	}
+}
+
+func TestCheckForbiddenSlots(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-10", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	diags := CheckForbiddenSlots(`course_id,department,slot
+CS101,,Fri afternoon
+,MATH,Saturday
+CS102,,2025-01-13
+`, slots)
+
+	expected := []string{
+		`forbidden slots:3: slot: warning: "Saturday" matches no slot, so the row has no effect`,
+		`forbidden slots:4: slot: warning: "2025-01-13" matches no slot, so the row has no effect`,
+	}
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %v", len(expected), diags)
+	}
+	for i, e := range expected {
+		if got := diags[i].String(); got != e {
+			t.Errorf("diagnostic %d: expected %s, got %s", i, e, got)
+		}
+	}
+}
+
+func TestRestrictAllowedSlots_Forbidden(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-07", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	courses := map[CourseID]*Course{
+		"MATH101": {ID: "MATH101", Enrollments: []StudentID{"s1"}},
+		"CS101":   {ID: "CS101", Enrollments: []StudentID{"s2"}},
+	}
+	rules, _ := ParseForbiddenSlots(`course_id,department,slot
+,MATH,2025-01-06
+CS101,,afternoon
+`)
+	allowed := map[CourseID]map[SlotID]bool{"CS101": {slots[0].ID: true, slots[1].ID: true}}
+
+	restricted, err := RestrictAllowedSlots(allowed, courses, slots, &Constraints{Forbidden: rules})
+	if err != nil {
+		t.Fatalf("RestrictAllowedSlots failed: %v", err)
+	}
+	if len(restricted["MATH101"]) != 2 || restricted["MATH101"][slots[0].ID] {
+		t.Errorf("expected MATH101 to be limited to the second day, got %v", restricted["MATH101"])
+	}
+	if len(restricted["CS101"]) != 1 || !restricted["CS101"][slots[0].ID] {
+		t.Errorf("expected CS101 to keep only its allowed morning slot, got %v", restricted["CS101"])
+	}
+}
+
+func TestVerifyScheduleWithConstraints_Forbidden(t *testing.T) {
+	_, regs, _ := ParseRegistrations(`student_id,course_id
+s1,CS101
+`, nil)
+	slots, _ := GenerateSlots("2025-01-10", "2025-01-10", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	scheduleCSV := `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+CS101,2025-01-10T14:00Z#2,2025-01-10T14:00:00Z,H1,1,
+`
+	halls, _ := ParseHalls("hall,capacity\nH1,10\n", nil)
+	rules, _ := ParseForbiddenSlots("course_id,slot\nCS101,Friday afternoon\n")
+
+	report, err := VerifyScheduleWithConstraints(regs, scheduleCSV, halls, slots, &Constraints{Forbidden: rules})
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithConstraints failed: %v", err)
+	}
+	if report.Valid || len(report.ConstraintViolations) != 1 {
+		t.Errorf("expected 1 forbidden slot violation, got %+v", report)
+	}
+}
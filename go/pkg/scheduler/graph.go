@@ -1,5 +1,7 @@
 package scheduler
 
+import "sort"
+
 // ConflictGraph represents the course conflict graph.
 // Nodes are courses, and edges represent shared students.
 type ConflictGraph struct {
@@ -15,11 +17,13 @@ func NewConflictGraph(courses map[CourseID]*Course) *ConflictGraph {
 	courseList := make([]CourseID, 0, numCourses)
 	courseIndex := make(map[CourseID]int, numCourses)
 
-	i := 0
+	// Sorted, so that the same seed colours the graph the same way on every run
 	for courseID := range courses {
 		courseList = append(courseList, courseID)
+	}
+	sort.Slice(courseList, func(i, j int) bool { return courseList[i] < courseList[j] })
+	for i, courseID := range courseList {
 		courseIndex[courseID] = i
-		i++
 	}
 
 	adjMatrix := make([][]int, numCourses)
@@ -13,24 +13,49 @@ func AllocateHalls(
 	usedHalls map[SlotID]map[HallID]bool,
 	slotID SlotID,
 ) (map[CourseID][]HallID, []string, error) {
+	return AllocateHallsWithAvailability(assignmentsInSlot, allHalls, usedHalls, slotID, nil)
+}
+
+// AllocateHallsWithAvailability runs AllocateHalls without the halls in unavailableHalls (see
+// Constraints.UnavailableHalls); it may be nil.
+func AllocateHallsWithAvailability(
+	assignmentsInSlot []*Assignment,
+	allHalls []*Hall,
+	usedHalls map[SlotID]map[HallID]bool,
+	slotID SlotID,
+	unavailableHalls map[HallID]bool,
+) (map[CourseID][]HallID, []string, error) {
 
 	allocatedHalls := make(map[CourseID][]HallID)
 	var capacityWarnings []string
 
-	// Sort assignments by enrollment, descending, for deterministic packing
-	sort.Slice(assignmentsInSlot, func(i, j int) bool {
-		return assignmentsInSlot[i].EnrolledCount > assignmentsInSlot[j].EnrolledCount
+	// Sort assignments by enrollment, descending, then by course ID for deterministic packing.
+	// Courses that need hall features go first so that other courses do not take the few halls
+	// that have them.
+	sort.SliceStable(assignmentsInSlot, func(i, j int) bool {
+		ri, rj := len(assignmentsInSlot[i].RequiredFeatures) > 0, len(assignmentsInSlot[j].RequiredFeatures) > 0
+		if ri != rj {
+			return ri
+		}
+		if assignmentsInSlot[i].EnrolledCount != assignmentsInSlot[j].EnrolledCount {
+			return assignmentsInSlot[i].EnrolledCount > assignmentsInSlot[j].EnrolledCount
+		}
+		return assignmentsInSlot[i].CourseID < assignmentsInSlot[j].CourseID
 	})
 
 	// Available halls for this slot
 	availableHalls := make([]*Hall, 0, len(allHalls))
 	for _, hall := range allHalls {
+		if unavailableHalls[hall.ID] {
+			continue
+		}
 		if used, ok := usedHalls[slotID]; !ok || !used[hall.ID] {
 			availableHalls = append(availableHalls, hall)
 		}
 	}
-	// Sort available halls by capacity, ascending, to find tightest fit
-	sort.Slice(availableHalls, func(i, j int) bool {
+	// Sort available halls by capacity, ascending, to find tightest fit; halls of the same size
+	// keep their input order
+	sort.SliceStable(availableHalls, func(i, j int) bool {
 		return availableHalls[i].Capacity < availableHalls[j].Capacity
 	})
 
@@ -38,67 +63,72 @@ func AllocateHalls(
 		usedHalls[slotID] = make(map[HallID]bool)
 	}
 
+	removeHall := func(id HallID) {
+		for i, ah := range availableHalls {
+			if ah.ID == id {
+				availableHalls = append(availableHalls[:i], availableHalls[i+1:]...)
+				return
+			}
+		}
+	}
+
+	// Seat students who need a designated room first, so regular seating cannot take those rooms.
+	seatedInDesignated := make(map[CourseID]int)
 	for _, assignment := range assignmentsInSlot {
-		neededCapacity := assignment.EnrolledCount
-		var assignedHallsForCourse []HallID
-
-		// Find the best single hall first
-		bestFitIndex := -1
-		for i, hall := range availableHalls {
-			if hall.Capacity >= neededCapacity {
-				if bestFitIndex == -1 || hall.Capacity < availableHalls[bestFitIndex].Capacity {
-					bestFitIndex = i
-				}
+		if assignment.AccommodatedCount == 0 {
+			continue
+		}
+		var designated []*Hall
+		for _, hall := range availableHalls {
+			if hall.Accommodation && hall.HasFeatures(assignment.RequiredFeatures) {
+				designated = append(designated, hall)
 			}
 		}
 
-		if bestFitIndex != -1 {
-			hall := availableHalls[bestFitIndex]
-			assignedHallsForCourse = append(assignedHallsForCourse, hall.ID)
+		chosen, capacity := packHalls(assignment.AccommodatedCount, designated)
+		if capacity < assignment.AccommodatedCount {
+			msg := fmt.Sprintf("course %s has %d students needing a designated room but only %d designated seats are available; the rest are seated with the other students", assignment.CourseID, assignment.AccommodatedCount, capacity)
+			capacityWarnings = append(capacityWarnings, msg)
+		}
+		for _, hall := range chosen {
+			allocatedHalls[assignment.CourseID] = append(allocatedHalls[assignment.CourseID], hall.ID)
 			usedHalls[slotID][hall.ID] = true
-			// Remove from available list for this slot
-			availableHalls = append(availableHalls[:bestFitIndex], availableHalls[bestFitIndex+1:]...)
-		} else {
-			// Try to combine multiple smaller halls (greedy approach)
-			// Sort remaining halls by capacity descending to fill up faster
-			sort.Slice(availableHalls, func(i, j int) bool {
-				return availableHalls[i].Capacity > availableHalls[j].Capacity
-			})
-
-			currentCapacity := 0
-			var combination []*Hall
+			removeHall(hall.ID)
+		}
+		// The other students of the course fill the seats left in its designated rooms.
+		seatedInDesignated[assignment.CourseID] = min(capacity, assignment.EnrolledCount)
+	}
+
+	for _, assignment := range assignmentsInSlot {
+		neededCapacity := assignment.EnrolledCount - seatedInDesignated[assignment.CourseID]
+		if neededCapacity == 0 && len(allocatedHalls[assignment.CourseID]) > 0 {
+			continue
+		}
+
+		suitable := availableHalls
+		if len(assignment.RequiredFeatures) > 0 {
+			suitable = nil
 			for _, hall := range availableHalls {
-				if currentCapacity < neededCapacity {
-					currentCapacity += hall.Capacity
-					combination = append(combination, hall)
+				if hall.HasFeatures(assignment.RequiredFeatures) {
+					suitable = append(suitable, hall)
 				}
 			}
+		}
 
-			if currentCapacity >= neededCapacity {
-				for _, hall := range combination {
-					assignedHallsForCourse = append(assignedHallsForCourse, hall.ID)
-					usedHalls[slotID][hall.ID] = true
-					// Remove from available list
-					for i, ah := range availableHalls {
-						if ah.ID == hall.ID {
-							availableHalls = append(availableHalls[:i], availableHalls[i+1:]...)
-							break
-						}
-					}
-				}
-			} else {
-				// Not enough capacity even with all remaining halls
-				msg := fmt.Sprintf("course %s (enrolled: %d) could not be fully allocated. Total available capacity: %d", assignment.CourseID, neededCapacity, currentCapacity)
-				capacityWarnings = append(capacityWarnings, msg)
-				// Assign what's available anyway
-				for _, hall := range availableHalls {
-					assignedHallsForCourse = append(assignedHallsForCourse, hall.ID)
-					usedHalls[slotID][hall.ID] = true
-				}
-				availableHalls = nil // All used up
+		chosen, currentCapacity := packHalls(neededCapacity, suitable)
+		if currentCapacity < neededCapacity {
+			// Not enough capacity even with all remaining suitable halls
+			msg := fmt.Sprintf("course %s (enrolled: %d) could not be fully allocated. Total available capacity: %d", assignment.CourseID, neededCapacity, currentCapacity)
+			if len(assignment.RequiredFeatures) > 0 {
+				msg += fmt.Sprintf(" (%s)", featureShortage(assignment.RequiredFeatures, neededCapacity, allHalls, unavailableHalls))
 			}
+			capacityWarnings = append(capacityWarnings, msg)
+		}
+		for _, hall := range chosen {
+			allocatedHalls[assignment.CourseID] = append(allocatedHalls[assignment.CourseID], hall.ID)
+			usedHalls[slotID][hall.ID] = true
+			removeHall(hall.ID)
 		}
-		allocatedHalls[assignment.CourseID] = assignedHallsForCourse
 	}
 
 	// Update the main assignment objects
@@ -115,3 +145,53 @@ func AllocateHalls(
 
 	return allocatedHalls, capacityWarnings, nil
 }
+
+// featureShortage explains why too few halls with the required features were available.
+func featureShortage(required []string, needed int, allHalls []*Hall, unavailableHalls map[HallID]bool) string {
+	total := 0
+	for _, hall := range allHalls {
+		if hall.HasFeatures(required) && !unavailableHalls[hall.ID] {
+			total += hall.Capacity
+		}
+	}
+	if total == 0 {
+		return fmt.Sprintf("no hall available in this slot has the required features [%s]", strings.Join(required, ";"))
+	}
+	if total < needed {
+		return fmt.Sprintf("halls with the required features [%s] seat only %d in total", strings.Join(required, ";"), total)
+	}
+	return fmt.Sprintf("halls with the required features [%s] are in use by other courses in this slot", strings.Join(required, ";"))
+}
+
+// packHalls chooses halls from available to seat needed students: the tightest single hall if
+// one is big enough, otherwise the largest halls until the need is met. If even all halls
+// together are too small, all of them are returned. It also returns their total capacity.
+func packHalls(needed int, available []*Hall) ([]*Hall, int) {
+	// Find the best single hall first
+	var bestFit *Hall
+	for _, hall := range available {
+		if hall.Capacity >= needed && (bestFit == nil || hall.Capacity < bestFit.Capacity) {
+			bestFit = hall
+		}
+	}
+	if bestFit != nil {
+		return []*Hall{bestFit}, bestFit.Capacity
+	}
+
+	// Combine multiple smaller halls (greedy approach), largest first to fill up faster
+	byCapacity := make([]*Hall, len(available))
+	copy(byCapacity, available)
+	sort.SliceStable(byCapacity, func(i, j int) bool {
+		return byCapacity[i].Capacity > byCapacity[j].Capacity
+	})
+
+	currentCapacity := 0
+	var combination []*Hall
+	for _, hall := range byCapacity {
+		if currentCapacity < needed {
+			currentCapacity += hall.Capacity
+			combination = append(combination, hall)
+		}
+	}
+	return combination, currentCapacity
+}
@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -79,3 +80,107 @@ func TestAllocateHalls_CapacityWarning(t *testing.T) {
 		t.Fatalf("expected 1 capacity warning, got %d", len(warnings))
 	}
 }
+
+func TestAllocateHalls_DesignatedRooms(t *testing.T) {
+	assignments := []*Assignment{
+		{CourseID: "c1", EnrolledCount: 40, AccommodatedCount: 2},
+		{CourseID: "c2", EnrolledCount: 5},
+	}
+	halls := []*Hall{
+		{ID: "H1", Capacity: 50},
+		{ID: "Quiet", Capacity: 5, Accommodation: true},
+	}
+	usedHalls := make(map[SlotID]map[HallID]bool)
+
+	_, warnings, err := AllocateHalls(assignments, halls, usedHalls, "slot1")
+	if err != nil {
+		t.Fatalf("AllocateHalls failed: %v", err)
+	}
+
+	// c1 takes the designated room for its 2 accommodated students and H1 for the other 38,
+	// which leaves nothing for c2.
+	if assignments[0].Halls != "H1;Quiet" {
+		t.Errorf("expected c1 to be in H1;Quiet, got %s", assignments[0].Halls)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected 1 capacity warning for c2, got %v", warnings)
+	}
+}
+
+func TestAllocateHalls_DesignatedRoomLeftoverSeats(t *testing.T) {
+	assignments := []*Assignment{{CourseID: "c1", EnrolledCount: 8, AccommodatedCount: 2}}
+	halls := []*Hall{
+		{ID: "H1", Capacity: 50},
+		{ID: "Quiet", Capacity: 10, Accommodation: true},
+	}
+	usedHalls := make(map[SlotID]map[HallID]bool)
+
+	_, warnings, err := AllocateHalls(assignments, halls, usedHalls, "slot1")
+	if err != nil {
+		t.Fatalf("AllocateHalls failed: %v", err)
+	}
+
+	// The 6 other students fit in the 8 seats left in the designated room.
+	if assignments[0].Halls != "Quiet" {
+		t.Errorf("expected c1 to be in Quiet only, got %s", assignments[0].Halls)
+	}
+	if len(warnings) != 0 || usedHalls["slot1"]["H1"] {
+		t.Errorf("expected H1 to stay free without warnings, got %v", warnings)
+	}
+}
+
+func TestAllocateHalls_RequiredFeatures(t *testing.T) {
+	assignments := []*Assignment{
+		{CourseID: "c1", EnrolledCount: 20},
+		{CourseID: "cad", EnrolledCount: 20, RequiredFeatures: []string{"lab"}},
+		{CourseID: "draw", EnrolledCount: 10, RequiredFeatures: []string{"drawing"}},
+	}
+	halls := []*Hall{
+		{ID: "Lab1", Capacity: 25, Features: []string{"accessible", "lab"}},
+		{ID: "H1", Capacity: 100},
+	}
+	usedHalls := make(map[SlotID]map[HallID]bool)
+
+	_, warnings, err := AllocateHalls(assignments, halls, usedHalls, "slot1")
+	if err != nil {
+		t.Fatalf("AllocateHalls failed: %v", err)
+	}
+
+	hallsByCourse := make(map[CourseID]string)
+	for _, a := range assignments {
+		hallsByCourse[a.CourseID] = a.Halls
+	}
+	// c1 would fit Lab1 more tightly, but cad needs it.
+	if hallsByCourse["cad"] != "Lab1" {
+		t.Errorf("expected cad in Lab1, got %q", hallsByCourse["cad"])
+	}
+	if hallsByCourse["c1"] != "H1" {
+		t.Errorf("expected c1 in H1, got %q", hallsByCourse["c1"])
+	}
+	if hallsByCourse["draw"] != "" {
+		t.Errorf("expected no hall for draw, got %q", hallsByCourse["draw"])
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "no hall available in this slot has the required features [drawing]") {
+		t.Errorf("expected a warning explaining the missing feature, got %v", warnings)
+	}
+}
+
+func TestAllocateHalls_UnavailableHalls(t *testing.T) {
+	assignments := []*Assignment{{CourseID: "c1", EnrolledCount: 40}}
+	halls := []*Hall{
+		{ID: "Sports", Capacity: 50},
+		{ID: "H1", Capacity: 100},
+	}
+	usedHalls := make(map[SlotID]map[HallID]bool)
+
+	_, warnings, err := AllocateHallsWithAvailability(assignments, halls, usedHalls, "slot1", map[HallID]bool{"Sports": true})
+	if err != nil {
+		t.Fatalf("AllocateHalls failed: %v", err)
+	}
+	if assignments[0].Halls != "H1" {
+		t.Errorf("expected c1 in H1 while Sports is unavailable, got %s", assignments[0].Halls)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+}
@@ -0,0 +1,412 @@
+package scheduler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StaffID is a unique identifier for an invigilator.
+type StaffID string
+
+// Staff represents a member of staff who can invigilate exams.
+type Staff struct {
+	ID          StaffID
+	Department  string
+	MaxDuties   int           // 0 means no limit
+	Unavailable []SlotPattern // Slots the staff member cannot invigilate
+	Courses     []CourseID    // Courses taught by the staff member
+}
+
+// InvigilationConfig controls how invigilators are assigned to halls.
+type InvigilationConfig struct {
+	StudentsPerInvigilator int  `json:"studentsPerInvigilator"` // One invigilator per this many students (default: 30)
+	NoOwnCourse            bool `json:"noOwnCourse"`            // Staff may not invigilate their own courses
+}
+
+// Duty is a single invigilation duty: a staff member in a hall during a slot.
+type Duty struct {
+	StaffID  StaffID    `json:"staffId"`
+	SlotID   SlotID     `json:"slotId"`
+	HallID   HallID     `json:"hallId"`
+	Courses  []CourseID `json:"courses"`
+	Students int        `json:"students"`
+}
+
+// RosterReport contains the results of a roster verification.
+type RosterReport struct {
+	Valid        bool            `json:"valid"`
+	Understaffed []string        `json:"understaffed"`
+	Overloaded   []string        `json:"overloaded"`
+	Errors       []string        `json:"errors"`
+	DutyCounts   map[StaffID]int `json:"dutyCounts"`
+}
+
+// hallSession is one hall in use during one slot, with the number of students seated there.
+type hallSession struct {
+	slotID   SlotID
+	start    string
+	hallID   HallID
+	courses  []CourseID
+	students int
+}
+
+// ParseStaff parses the staff CSV data.
+// Required columns are staff_id; department, max_duties, unavailable and courses are optional.
+// The unavailable column holds semicolon-separated slot patterns and the courses column
+// semicolon-separated course IDs. Rows that cannot be used are skipped; see
+// ParseStaffWithDiagnostics to find out which.
+func ParseStaff(csvData string) ([]*Staff, error) {
+	staff, _, err := ParseStaffWithDiagnostics(csvData, false)
+	return staff, err
+}
+
+// ParseStaffWithDiagnostics parses the staff CSV data like ParseStaff and also reports every
+// skipped row. In strict mode any skipped row is an error.
+func ParseStaffWithDiagnostics(csvData string, strict bool) ([]*Staff, []Diagnostic, error) {
+	table, err := newCSVTable("staff", csvData, "staff_id")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var staff []*Staff
+	for table.next() {
+		id := StaffID(table.field("staff_id"))
+		if id == "" {
+			table.skip("staff_id", "empty staff ID")
+			continue
+		}
+
+		member := &Staff{ID: id, Department: table.field("department")}
+		if v := table.field("max_duties"); v != "" {
+			maxDuties, err := strconv.Atoi(v)
+			if err != nil || maxDuties < 0 {
+				return nil, nil, fmt.Errorf("invalid max_duties %q for staff %s", v, id)
+			}
+			member.MaxDuties = maxDuties
+		}
+		unavailable, err := ParseSlotPatterns(table.field("unavailable"))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid unavailable slots for staff %s: %w", id, err)
+		}
+		member.Unavailable = unavailable
+		for _, c := range splitList(table.field("courses")) {
+			member.Courses = append(member.Courses, CourseID(c))
+		}
+		staff = append(staff, member)
+	}
+
+	diagnostics, err := table.result(strict)
+	if err != nil {
+		return nil, diagnostics, err
+	}
+	return staff, diagnostics, nil
+}
+
+// CourseDepartment derives a department code from a course ID by taking its leading letters,
+// e.g. "CS101" and "CS-101" both belong to "CS".
+func CourseDepartment(courseID CourseID) string {
+	id := strings.TrimSpace(string(courseID))
+	end := 0
+	for end < len(id) && (id[end] >= 'A' && id[end] <= 'Z' || id[end] >= 'a' && id[end] <= 'z') {
+		end++
+	}
+	return strings.ToUpper(id[:end])
+}
+
+// AssignInvigilators builds a duty roster for the halls used by the given assignments.
+// Each hall needs ceil(students / StudentsPerInvigilator) invigilators, where the students of a
+// course are seated in its halls largest first. Duties are handed out in slot order to the
+// eligible staff member with the fewest duties so far, so the load stays balanced.
+// Staff are eligible if none of their unavailable patterns selects the slot, below their duty limit, not already
+// on duty in the same slot and, with NoOwnCourse, not teaching any course sat in the hall.
+// Courses taught are taken from Staff.Courses, or from the department when no courses are listed.
+func AssignInvigilators(assignments []*Assignment, halls []*Hall, staff []*Staff, config InvigilationConfig) []*Duty {
+	return AssignInvigilatorsWithSittings(assignments, halls, staff, config, nil)
+}
+
+// AssignInvigilatorsWithSittings runs AssignInvigilators on a schedule with split courses, so staff
+// teaching a course count as teaching each of its sittings. The plan may be nil.
+func AssignInvigilatorsWithSittings(assignments []*Assignment, halls []*Hall, staff []*Staff, config InvigilationConfig, plan *SittingPlan) []*Duty {
+	if config.StudentsPerInvigilator <= 0 {
+		config.StudentsPerInvigilator = 30
+	}
+
+	sessions := hallSessions(assignments, halls)
+	dutyCount := make(map[StaffID]int, len(staff))
+	busy := make(map[SlotID]map[StaffID]bool)
+	var roster []*Duty
+
+	for _, session := range sessions {
+		needed := (session.students + config.StudentsPerInvigilator - 1) / config.StudentsPerInvigilator
+		if busy[session.slotID] == nil {
+			busy[session.slotID] = make(map[StaffID]bool)
+		}
+
+		for n := 0; n < needed; n++ {
+			var chosen *Staff
+			for _, member := range staff {
+				if busy[session.slotID][member.ID] {
+					continue
+				}
+				if member.MaxDuties > 0 && dutyCount[member.ID] >= member.MaxDuties {
+					continue
+				}
+				if isStaffUnavailable(member, session.slotID, session.start) {
+					continue
+				}
+				if config.NoOwnCourse && teachesAny(member, session.courses, plan) {
+					continue
+				}
+				if chosen == nil || dutyCount[member.ID] < dutyCount[chosen.ID] ||
+					(dutyCount[member.ID] == dutyCount[chosen.ID] && member.ID < chosen.ID) {
+					chosen = member
+				}
+			}
+			if chosen == nil {
+				break // Understaffed; reported by VerifyRoster
+			}
+
+			busy[session.slotID][chosen.ID] = true
+			dutyCount[chosen.ID]++
+			roster = append(roster, &Duty{
+				StaffID:  chosen.ID,
+				SlotID:   session.slotID,
+				HallID:   session.hallID,
+				Courses:  session.courses,
+				Students: session.students,
+			})
+		}
+	}
+
+	return roster
+}
+
+// VerifyRoster checks a duty roster against the schedule it was built for.
+// It flags understaffed halls, staff over their duty limit, staff on duty while unavailable
+// or twice in one slot, and, with NoOwnCourse, staff invigilating their own course.
+func VerifyRoster(roster []*Duty, assignments []*Assignment, halls []*Hall, staff []*Staff, config InvigilationConfig) *RosterReport {
+	return VerifyRosterWithSittings(roster, assignments, halls, staff, config, nil)
+}
+
+// VerifyRosterWithSittings runs VerifyRoster on a schedule with split courses; see
+// AssignInvigilatorsWithSittings. The plan may be nil.
+func VerifyRosterWithSittings(roster []*Duty, assignments []*Assignment, halls []*Hall, staff []*Staff, config InvigilationConfig, plan *SittingPlan) *RosterReport {
+	if config.StudentsPerInvigilator <= 0 {
+		config.StudentsPerInvigilator = 30
+	}
+	report := &RosterReport{Valid: true, DutyCounts: make(map[StaffID]int)}
+
+	staffMap := make(map[StaffID]*Staff, len(staff))
+	for _, member := range staff {
+		staffMap[member.ID] = member
+	}
+
+	type sessionKey struct {
+		slotID SlotID
+		hallID HallID
+	}
+	assigned := make(map[sessionKey]int)
+	busy := make(map[SlotID]map[StaffID]bool)
+	sessionStart := make(map[SlotID]string)
+	sessions := hallSessions(assignments, halls)
+	sessionCourses := make(map[sessionKey][]CourseID, len(sessions))
+	for _, s := range sessions {
+		sessionStart[s.slotID] = s.start
+		sessionCourses[sessionKey{s.slotID, s.hallID}] = s.courses
+	}
+
+	for _, duty := range roster {
+		report.DutyCounts[duty.StaffID]++
+		assigned[sessionKey{duty.SlotID, duty.HallID}]++
+
+		member, ok := staffMap[duty.StaffID]
+		if !ok {
+			report.Errors = append(report.Errors, fmt.Sprintf("unknown staff %s on duty in hall %s, slot %s", duty.StaffID, duty.HallID, duty.SlotID))
+			report.Valid = false
+			continue
+		}
+		if busy[duty.SlotID] == nil {
+			busy[duty.SlotID] = make(map[StaffID]bool)
+		}
+		if busy[duty.SlotID][member.ID] {
+			report.Errors = append(report.Errors, fmt.Sprintf("staff %s has more than one duty in slot %s", member.ID, duty.SlotID))
+			report.Valid = false
+		}
+		busy[duty.SlotID][member.ID] = true
+
+		if isStaffUnavailable(member, duty.SlotID, sessionStart[duty.SlotID]) {
+			report.Errors = append(report.Errors, fmt.Sprintf("staff %s is on duty in slot %s but unavailable", member.ID, duty.SlotID))
+			report.Valid = false
+		}
+		if config.NoOwnCourse && teachesAny(member, sessionCourses[sessionKey{duty.SlotID, duty.HallID}], plan) {
+			report.Errors = append(report.Errors, fmt.Sprintf("staff %s invigilates their own course in hall %s, slot %s", member.ID, duty.HallID, duty.SlotID))
+			report.Valid = false
+		}
+	}
+
+	for _, s := range sessions {
+		needed := (s.students + config.StudentsPerInvigilator - 1) / config.StudentsPerInvigilator
+		if got := assigned[sessionKey{s.slotID, s.hallID}]; got < needed {
+			report.Understaffed = append(report.Understaffed,
+				fmt.Sprintf("hall %s in slot %s has %d of %d invigilators for %d students", s.hallID, s.slotID, got, needed, s.students))
+			report.Valid = false
+		}
+	}
+
+	for _, member := range staff {
+		if member.MaxDuties > 0 && report.DutyCounts[member.ID] > member.MaxDuties {
+			report.Overloaded = append(report.Overloaded,
+				fmt.Sprintf("staff %s has %d duties, limit is %d", member.ID, report.DutyCounts[member.ID], member.MaxDuties))
+			report.Valid = false
+		}
+	}
+
+	return report
+}
+
+// SerializeRoster serializes a duty roster to a CSV string.
+func SerializeRoster(roster []*Duty) (string, error) {
+	var sb strings.Builder
+	writer := csv.NewWriter(&sb)
+
+	if err := writer.Write([]string{"staff_id", "slot_id", "hall", "courses", "students"}); err != nil {
+		return "", err
+	}
+	for _, d := range roster {
+		courses := make([]string, len(d.Courses))
+		for i, c := range d.Courses {
+			courses[i] = string(c)
+		}
+		record := []string{string(d.StaffID), string(d.SlotID), string(d.HallID), strings.Join(courses, ";"), strconv.Itoa(d.Students)}
+		if err := writer.Write(record); err != nil {
+			return "", err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// hallSessions lists every hall in use per slot, in chronological order, with the students seated there.
+// A course's students fill its halls largest first, so the last hall may be partly empty.
+func hallSessions(assignments []*Assignment, halls []*Hall) []*hallSession {
+	hallCapacity := make(map[HallID]int, len(halls))
+	for _, h := range halls {
+		hallCapacity[h.ID] = h.Capacity
+	}
+
+	type sessionKey struct {
+		slotID SlotID
+		hallID HallID
+	}
+	index := make(map[sessionKey]*hallSession)
+	var sessions []*hallSession
+
+	for _, a := range assignments {
+		var courseHalls []HallID
+		for _, h := range strings.Split(a.Halls, ";") {
+			if h != "" {
+				courseHalls = append(courseHalls, HallID(h))
+			}
+		}
+		sort.Slice(courseHalls, func(i, j int) bool {
+			if hallCapacity[courseHalls[i]] != hallCapacity[courseHalls[j]] {
+				return hallCapacity[courseHalls[i]] > hallCapacity[courseHalls[j]]
+			}
+			return courseHalls[i] < courseHalls[j]
+		})
+
+		remaining := a.EnrolledCount
+		for i, h := range courseHalls {
+			seated := hallCapacity[h]
+			if seated > remaining || i == len(courseHalls)-1 {
+				seated = remaining
+			}
+			remaining -= seated
+
+			key := sessionKey{a.SlotID, h}
+			session, ok := index[key]
+			if !ok {
+				session = &hallSession{slotID: a.SlotID, start: a.SlotDateTime, hallID: h}
+				index[key] = session
+				sessions = append(sessions, session)
+			}
+			session.courses = append(session.courses, a.CourseID)
+			session.students += seated
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].start != sessions[j].start {
+			return sessions[i].start < sessions[j].start
+		}
+		if sessions[i].slotID != sessions[j].slotID {
+			return sessions[i].slotID < sessions[j].slotID
+		}
+		return sessions[i].hallID < sessions[j].hallID
+	})
+	return sessions
+}
+
+// isStaffUnavailable reports whether any of the staff member's unavailable patterns selects the
+// slot, which starts at the given RFC 3339 time. Without a start time only slot IDs can match.
+func isStaffUnavailable(member *Staff, slotID SlotID, start string) bool {
+	t, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		for _, p := range member.Unavailable {
+			if p.slotID == slotID {
+				return true
+			}
+		}
+		return false
+	}
+	return matchesAny(member.Unavailable, &Slot{ID: slotID, Start: t, IndexInDay: indexInDay(slotID, t)})
+}
+
+// indexInDay recovers the index within the day from a slot ID made by GenerateSlots. Other IDs
+// give -1, which no slot index term matches.
+func indexInDay(slotID SlotID, start time.Time) int {
+	id := string(slotID)
+	if i := strings.LastIndex(id, "#"); i >= 0 {
+		if n, err := strconv.Atoi(id[i+1:]); err == nil && n >= 1 && slotIDFor(start, n-1) == slotID {
+			return n - 1
+		}
+	}
+	return -1
+}
+
+// teachesAny reports whether the staff member teaches any of the given courses; sittings in the
+// plan count as their course.
+func teachesAny(member *Staff, courses []CourseID, plan *SittingPlan) bool {
+	for _, c := range courses {
+		c = plan.Course(c)
+		if len(member.Courses) > 0 {
+			for _, own := range member.Courses {
+				if own == c {
+					return true
+				}
+			}
+		} else if member.Department != "" && strings.EqualFold(member.Department, CourseDepartment(c)) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a semicolon-separated list, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ";") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
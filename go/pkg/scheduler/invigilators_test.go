@@ -0,0 +1,159 @@
+package scheduler
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseStaff(t *testing.T) {
+	staff, err := ParseStaff(`staff_id,department,max_duties,unavailable,courses
+alice,CS,2,2025-01-06,CS101;CS102
+bob,MATH,,,
+# comment
+,CS,1,,
+`)
+	if err != nil {
+		t.Fatalf("ParseStaff failed: %v", err)
+	}
+	if len(staff) != 2 {
+		t.Fatalf("expected 2 staff, got %d", len(staff))
+	}
+	if staff[0].MaxDuties != 2 || len(staff[0].Courses) != 2 || staff[0].Unavailable[0].String() != "2025-01-06" {
+		t.Errorf("unexpected data for alice: %+v", staff[0])
+	}
+	if staff[1].MaxDuties != 0 {
+		t.Errorf("expected no duty limit for bob, got %d", staff[1].MaxDuties)
+	}
+}
+
+func TestCourseDepartment(t *testing.T) {
+	cases := map[CourseID]string{"CS101": "CS", "math-201": "MATH", "101": ""}
+	for id, want := range cases {
+		if got := CourseDepartment(id); got != want {
+			t.Errorf("CourseDepartment(%s) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestAssignInvigilators_Balanced(t *testing.T) {
+	assignments := []*Assignment{
+		{CourseID: "CS101", SlotID: "slot1", SlotDateTime: "2025-01-06T09:00:00Z", Halls: "H1;H2", EnrolledCount: 70},
+		{CourseID: "MATH101", SlotID: "slot2", SlotDateTime: "2025-01-07T09:00:00Z", Halls: "H1", EnrolledCount: 20},
+	}
+	halls := []*Hall{{ID: "H1", Capacity: 50}, {ID: "H2", Capacity: 30}}
+	staff := []*Staff{
+		{ID: "alice", Department: "CS"},
+		{ID: "bob", Department: "MATH"},
+		{ID: "carol", Department: "PHYS"},
+		{ID: "dave", Department: "PHYS"},
+	}
+	config := InvigilationConfig{StudentsPerInvigilator: 30, NoOwnCourse: true}
+
+	roster := AssignInvigilators(assignments, halls, staff, config)
+
+	// H1 seats 50 CS101 students (2 invigilators), H2 the other 20 (1), and MATH101 needs 1.
+	if len(roster) != 4 {
+		t.Fatalf("expected 4 duties, got %d", len(roster))
+	}
+	for _, d := range roster {
+		if d.StaffID == "alice" && d.SlotID == "slot1" {
+			t.Error("alice should not invigilate their own department's course")
+		}
+		if d.StaffID == "bob" && d.SlotID == "slot2" {
+			t.Error("bob should not invigilate their own department's course")
+		}
+	}
+
+	report := VerifyRoster(roster, assignments, halls, staff, config)
+	if !report.Valid {
+		t.Errorf("roster should be valid, got %+v", report)
+	}
+}
+
+func TestAssignInvigilators_UnavailablePatterns(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-07", 2, []string{"09:00", "14:00"}, 120, nil, "UTC")
+	var assignments []*Assignment
+	for i, slot := range slots {
+		assignments = append(assignments, &Assignment{
+			CourseID:      CourseID(fmt.Sprintf("C%d", i)),
+			SlotID:        slot.ID,
+			SlotDateTime:  slot.Start.Format(time.RFC3339),
+			Halls:         "H1",
+			EnrolledCount: 10,
+		})
+	}
+	halls := []*Hall{{ID: "H1", Capacity: 50}}
+	staff, err := ParseStaff(`staff_id,unavailable
+alice,Mon afternoon;#1
+bob,2025-01-07
+`)
+	if err != nil {
+		t.Fatalf("ParseStaff failed: %v", err)
+	}
+	config := InvigilationConfig{StudentsPerInvigilator: 30}
+
+	// Only bob can take Monday, nobody Tuesday morning and only alice Tuesday afternoon.
+	roster := AssignInvigilators(assignments, halls, staff, config)
+	got := make(map[SlotID]StaffID)
+	for _, d := range roster {
+		got[d.SlotID] = d.StaffID
+	}
+	want := map[SlotID]StaffID{slots[0].ID: "bob", slots[1].ID: "bob", slots[3].ID: "alice"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected duties %v, got %v", want, got)
+	}
+
+	roster = append(roster, &Duty{StaffID: "alice", SlotID: slots[2].ID, HallID: "H1"})
+	report := VerifyRoster(roster, assignments, halls, staff, config)
+	if len(report.Errors) != 1 || len(report.Understaffed) != 0 {
+		t.Errorf("expected alice to be reported unavailable on Tuesday morning, got %+v", report)
+	}
+}
+
+func TestAssignInvigilatorsWithSittings(t *testing.T) {
+	assignments := []*Assignment{
+		{CourseID: "BIG/S1", SlotID: "slot1", SlotDateTime: "2025-01-06T09:00:00Z", Halls: "H1", EnrolledCount: 20},
+	}
+	halls := []*Hall{{ID: "H1", Capacity: 50}}
+	staff := []*Staff{{ID: "alice", Courses: []CourseID{"BIG"}}, {ID: "bob", Courses: []CourseID{"MA101"}}}
+	plan := &SittingPlan{Sittings: map[CourseID][]CourseID{"BIG": {"BIG/S1"}}}
+	config := InvigilationConfig{StudentsPerInvigilator: 30, NoOwnCourse: true}
+
+	roster := AssignInvigilatorsWithSittings(assignments, halls, staff, config, plan)
+	if len(roster) != 1 || roster[0].StaffID != "bob" {
+		t.Fatalf("expected bob to invigilate the sitting of alice's course, got %+v", roster)
+	}
+
+	roster[0].StaffID = "alice"
+	if report := VerifyRosterWithSittings(roster, assignments, halls, staff, config, plan); report.Valid {
+		t.Error("expected alice invigilating a sitting of their own course to be reported")
+	}
+}
+
+func TestVerifyRoster_UnderstaffedAndOverloaded(t *testing.T) {
+	assignments := []*Assignment{
+		{CourseID: "c1", SlotID: "slot1", SlotDateTime: "2025-01-06T09:00:00Z", Halls: "H1", EnrolledCount: 60},
+		{CourseID: "c2", SlotID: "slot2", SlotDateTime: "2025-01-06T14:00:00Z", Halls: "H1", EnrolledCount: 10},
+	}
+	halls := []*Hall{{ID: "H1", Capacity: 100}}
+	staff := []*Staff{{ID: "alice", MaxDuties: 1}}
+	config := InvigilationConfig{StudentsPerInvigilator: 30}
+
+	roster := AssignInvigilators(assignments, halls, staff, config)
+	report := VerifyRoster(roster, assignments, halls, staff, config)
+	if report.Valid {
+		t.Error("roster should be invalid")
+	}
+	if len(report.Understaffed) != 2 {
+		t.Errorf("expected 2 understaffed halls, got %v", report.Understaffed)
+	}
+
+	// A hand-edited roster that exceeds the limit is flagged as overloaded.
+	roster = append(roster, &Duty{StaffID: "alice", SlotID: "slot2", HallID: "H1"})
+	report = VerifyRoster(roster, assignments, halls, staff, config)
+	if len(report.Overloaded) != 1 {
+		t.Errorf("expected 1 overloaded staff, got %v", report.Overloaded)
+	}
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
@@ -10,107 +11,325 @@ import (
 	"github.com/gocarina/gocsv"
 )
 
-// ParseRegistrations parses the registrations CSV data with custom column mapping.
-func ParseRegistrations(csvData string, columnMapping *ColumnMapping) (map[CourseID]*Course, []Registration, error) {
-	courses := make(map[CourseID]*Course)
-	var registrations []Registration
+// recordReader yields the rows of an input file, from CSV data or a spreadsheet.
+type recordReader interface {
+	Read() ([]string, error)
+	Line() int // Line or row number of the record most recently read
+}
 
-	reader := strings.NewReader(csvData)
+// csvRecords reads CSV data with the settings shared by the input parsers.
+type csvRecords struct {
+	*csv.Reader
+}
 
+func newCSVRecords(r io.Reader) csvRecords {
 	// Custom CSV reader configuration
-	csvReader := csv.NewReader(reader)
+	csvReader := csv.NewReader(r)
 	csvReader.LazyQuotes = true
 	csvReader.TrimLeadingSpace = true
 	csvReader.FieldsPerRecord = -1 // Allow variable number of columns
 	csvReader.Comment = '#'
+	return csvRecords{csvReader}
+}
+
+func (r csvRecords) Line() int {
+	return recordLine(r.Reader)
+}
+
+// csvTable reads an input CSV with a header row by column name. Rows that cannot be read or are
+// too short for the required columns are recorded as diagnostics and skipped.
+type csvTable struct {
+	rows     recordReader
+	diags    *diagnostics
+	columns  map[string]int
+	required int // Fields a row needs to hold every required column
+	record   []string
+	line     int
+}
+
+// newCSVTable reads the header of CSV data. Every required column must be present.
+func newCSVTable(file, csvData string, required ...string) (*csvTable, error) {
+	t := &csvTable{rows: newCSVRecords(strings.NewReader(csvData)), diags: &diagnostics{file: file}, columns: make(map[string]int)}
+	header, err := t.rows.Read()
+	if err != nil {
+		return nil, err
+	}
+	for i, col := range header {
+		t.columns[col] = i
+	}
+
+	var missing []string
+	for _, col := range required {
+		if i, ok := t.columns[col]; ok {
+			t.required = max(t.required, i+1)
+		} else {
+			missing = append(missing, col)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, missingColumnsError(required)
+	}
+	return t, nil
+}
+
+// missingColumnsError lists the required columns, as "missing required columns: a, b or c".
+func missingColumnsError(required []string) error {
+	if len(required) == 1 {
+		return fmt.Errorf("missing required column: %s", required[0])
+	}
+	return fmt.Errorf("missing required columns: %s or %s", strings.Join(required[:len(required)-1], ", "), required[len(required)-1])
+}
+
+// has reports whether the header has the column.
+func (t *csvTable) has(column string) bool {
+	_, ok := t.columns[column]
+	return ok
+}
+
+// next reads the next usable row and reports whether there is one.
+func (t *csvTable) next() bool {
+	for {
+		record, err := t.rows.Read()
+		if err == io.EOF {
+			return false
+		}
+		if err != nil {
+			t.diags.readError(err)
+			continue
+		}
+		t.record, t.line = record, t.rows.Line()
+		if len(record) < t.required {
+			t.skip("", "row has %d fields, expected at least %d", len(record), t.required)
+			continue
+		}
+		return true
+	}
+}
+
+// field returns the trimmed value of a column in the current row, or "" if it has none.
+func (t *csvTable) field(column string) string {
+	if i, ok := t.columns[column]; ok && i < len(t.record) {
+		return strings.TrimSpace(t.record[i])
+	}
+	return ""
+}
+
+// skip records that the current row is skipped, and why.
+func (t *csvTable) skip(column, format string, args ...any) {
+	t.diags.add(SeverityError, t.line, column, format, args...)
+}
+
+// result returns the diagnostics and, in strict mode, an error if any row was skipped.
+func (t *csvTable) result(strict bool) ([]Diagnostic, error) {
+	if strict {
+		if err := t.diags.strictError(); err != nil {
+			return t.diags.items, err
+		}
+	}
+	return t.diags.items, nil
+}
+
+// ParseRegistrations parses the registrations CSV data with custom column mapping. The data is
+// either long, with one student and course per row, or wide, with one row per student and the
+// courses in several columns or one delimited column; see ColumnMapping. Repeated registrations
+// of a student for a course are counted once. Rows that cannot be used are skipped; see
+// ParseRegistrationsWithDiagnostics to find out which.
+func ParseRegistrations(csvData string, columnMapping *ColumnMapping) (map[CourseID]*Course, []Registration, error) {
+	courses, registrations, _, err := ParseRegistrationsWithDiagnostics(csvData, columnMapping, false)
+	return courses, registrations, err
+}
+
+// ParseRegistrationsWithDiagnostics parses the registrations CSV data like ParseRegistrations and
+// also reports every skipped or suspicious row with its line, column and reason: repeated
+// registrations (which are ignored) and IDs differing only in case or spacing are warnings.
+// In strict mode any skipped row other than a repeated registration is an error.
+func ParseRegistrationsWithDiagnostics(csvData string, columnMapping *ColumnMapping, strict bool) (map[CourseID]*Course, []Registration, []Diagnostic, error) {
+	return ReadRegistrations(strings.NewReader(csvData), columnMapping, strict)
+}
+
+// ReadRegistrations reads registrations CSV data like ParseRegistrationsWithDiagnostics, one row
+// at a time, so the file never has to be held in memory as a whole.
+func ReadRegistrations(r io.Reader, columnMapping *ColumnMapping, strict bool) (map[CourseID]*Course, []Registration, []Diagnostic, error) {
+	return parseRegistrations(newCSVRecords(r), columnMapping, strict)
+}
+
+// parseRegistrations reads registrations from CSV records or spreadsheet rows.
+func parseRegistrations(rows recordReader, columnMapping *ColumnMapping, strict bool) (map[CourseID]*Course, []Registration, []Diagnostic, error) {
+	courses := make(map[CourseID]*Course)
+	var registrations []Registration
+	diags := &diagnostics{file: "registrations"}
 
 	// Read header to find column indices
-	header, err := csvReader.Read()
+	header, err := rows.Read()
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	// Use provided column names or defaults
 	studentIDCol := "student_id"
-	courseIDCol := "course_id"
+	courseCols := []string{"course_id"}
+	delimiter := ""
 	if columnMapping != nil {
 		if columnMapping.StudentIDColumn != "" {
 			studentIDCol = columnMapping.StudentIDColumn
 		}
-		if columnMapping.CourseIDColumn != "" {
-			courseIDCol = columnMapping.CourseIDColumn
+		if len(columnMapping.CourseColumns) > 0 {
+			courseCols = columnMapping.CourseColumns
+		} else if columnMapping.CourseIDColumn != "" {
+			courseCols = []string{columnMapping.CourseIDColumn}
 		}
+		delimiter = columnMapping.CourseDelimiter
 	}
+	// Wide files have one row per student, which may list no courses
+	wide := len(courseCols) > 1 || delimiter != ""
 
 	studentIDIndex := -1
-	courseIDIndex := -1
+	courseIndexes := make([]int, len(courseCols))
+	for i := range courseIndexes {
+		courseIndexes[i] = -1
+	}
 	for i, col := range header {
 		if col == studentIDCol {
 			studentIDIndex = i
 		}
-		if col == courseIDCol {
-			courseIDIndex = i
+		for j, courseCol := range courseCols {
+			if col == courseCol {
+				courseIndexes[j] = i
+			}
 		}
 	}
 
-	if studentIDIndex == -1 || courseIDIndex == -1 {
-		return nil, nil, fmt.Errorf("missing required columns: %s or %s", studentIDCol, courseIDCol)
+	missing := false
+	for _, index := range courseIndexes {
+		missing = missing || index == -1
+	}
+	if studentIDIndex == -1 || missing {
+		return nil, nil, nil, fmt.Errorf("missing required columns: %s or %s", studentIDCol, strings.Join(courseCols, ", "))
 	}
 
+	seen := make(map[Registration]int)
+	studentVariants, courseVariants := make(idVariants), make(idVariants)
 	for {
-		record, err := csvReader.Read()
+		record, err := rows.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			// Skip records with parsing errors, maybe log them
+			diags.readError(err)
 			continue
 		}
+		line := rows.Line()
 
-		if len(record) <= studentIDIndex || len(record) <= courseIDIndex {
-			continue // Skip malformed rows
+		if !wide && (len(record) <= studentIDIndex || len(record) <= courseIndexes[0]) {
+			diags.add(SeverityError, line, "", "row has %d fields, expected at least %d", len(record), max(studentIDIndex, courseIndexes[0])+1)
+			continue
+		}
+		if len(record) <= studentIDIndex {
+			diags.add(SeverityError, line, "", "row has %d fields, expected at least %d", len(record), studentIDIndex+1)
+			continue
 		}
 
 		studentID := StudentID(record[studentIDIndex])
-		courseID := CourseID(record[courseIDIndex])
-
-		if studentID == "" || courseID == "" {
-			continue // Skip rows with empty required fields
+		if studentID == "" {
+			diags.add(SeverityError, line, studentIDCol, "empty student ID")
+			continue
 		}
-
-		reg := Registration{StudentID: studentID, CourseID: courseID}
-		registrations = append(registrations, reg)
-
-		if _, ok := courses[courseID]; !ok {
-			courses[courseID] = &Course{ID: courseID}
+		if !wide && record[courseIndexes[0]] == "" {
+			diags.add(SeverityError, line, courseCols[0], "empty course ID")
+			continue
+		}
+		if strings.TrimSpace(string(studentID)) != string(studentID) {
+			diags.add(SeverityWarning, line, studentIDCol, "student ID %q has surrounding spaces", studentID)
+		}
+		studentVariants.check(diags, line, studentIDCol, "student", string(studentID))
+
+		listed := 0
+		for j, index := range courseIndexes {
+			if index >= len(record) {
+				continue // Short rows of a wide file leave the last course columns empty
+			}
+			cells := []string{record[index]}
+			if delimiter != "" {
+				cells = nil
+				for _, cell := range strings.Split(record[index], delimiter) {
+					cells = append(cells, strings.TrimSpace(cell))
+				}
+			}
+
+			for _, cell := range cells {
+				courseID := CourseID(cell)
+				if courseID == "" {
+					continue
+				}
+				listed++
+				if strings.TrimSpace(string(courseID)) != string(courseID) {
+					diags.add(SeverityWarning, line, courseCols[j], "course ID %q has surrounding spaces", courseID)
+				}
+				courseVariants.check(diags, line, courseCols[j], "course", string(courseID))
+
+				reg := Registration{StudentID: studentID, CourseID: courseID}
+				if first, ok := seen[reg]; ok {
+					diags.add(SeverityWarning, line, "", "student %s is registered for course %s again (first on line %d); the duplicate is ignored", studentID, courseID, first)
+					continue
+				}
+				seen[reg] = line
+				registrations = append(registrations, reg)
+
+				if _, ok := courses[courseID]; !ok {
+					courses[courseID] = &Course{ID: courseID}
+				}
+				courses[courseID].Enrollments = append(courses[courseID].Enrollments, studentID)
+			}
+		}
+		if listed == 0 {
+			diags.add(SeverityWarning, line, "", "no courses listed for student %s", studentID)
 		}
-		courses[courseID].Enrollments = append(courses[courseID].Enrollments, studentID)
 	}
 
-	return courses, registrations, nil
+	if strict {
+		if err := diags.strictError(); err != nil {
+			return nil, nil, diags.items, err
+		}
+	}
+	return courses, registrations, diags.items, nil
 }
 
 // ParseHalls parses the halls CSV data with custom column mapping.
+// A capacity is read up to its first non-digit, so "100 seats" is 100.
+// Rows that cannot be used are skipped; see ParseHallsWithDiagnostics to find out which.
 func ParseHalls(csvData string, columnMapping *ColumnMapping) ([]*Hall, error) {
-	reader := strings.NewReader(csvData)
+	halls, _, err := ParseHallsWithDiagnostics(csvData, columnMapping, false)
+	return halls, err
+}
 
-	// Custom CSV reader configuration
-	csvReader := csv.NewReader(reader)
-	csvReader.LazyQuotes = true
-	csvReader.TrimLeadingSpace = true
-	csvReader.FieldsPerRecord = -1 // Allow variable number of columns
-	csvReader.Comment = '#'
+// ParseHallsWithDiagnostics parses the halls CSV data like ParseHalls and also reports every
+// skipped or suspicious row with its line, column and reason. A hall defined twice keeps its
+// first row. In strict mode any skipped row is an error.
+func ParseHallsWithDiagnostics(csvData string, columnMapping *ColumnMapping, strict bool) ([]*Hall, []Diagnostic, error) {
+	return ReadHalls(strings.NewReader(csvData), columnMapping, strict)
+}
+
+// ReadHalls reads halls CSV data like ParseHallsWithDiagnostics, one row at a time.
+func ReadHalls(r io.Reader, columnMapping *ColumnMapping, strict bool) ([]*Hall, []Diagnostic, error) {
+	return parseHalls(newCSVRecords(r), columnMapping, strict)
+}
+
+// parseHalls reads halls from CSV records or spreadsheet rows.
+func parseHalls(rows recordReader, columnMapping *ColumnMapping, strict bool) ([]*Hall, []Diagnostic, error) {
+	diags := &diagnostics{file: "halls"}
 
 	// Read header to find column indices
-	header, err := csvReader.Read()
+	header, err := rows.Read()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Use provided column names or defaults
 	hallIDCol := "hall"
 	capacityCol := "capacity"
 	groupCol := "group"
+	accommodationCol := "accommodation"
+	featuresCol := "features"
 	if columnMapping != nil {
 		if columnMapping.HallIDColumn != "" {
 			hallIDCol = columnMapping.HallIDColumn
@@ -121,11 +340,19 @@ func ParseHalls(csvData string, columnMapping *ColumnMapping) ([]*Hall, error) {
 		if columnMapping.GroupColumn != "" {
 			groupCol = columnMapping.GroupColumn
 		}
+		if columnMapping.AccommodationColumn != "" {
+			accommodationCol = columnMapping.AccommodationColumn
+		}
+		if columnMapping.FeaturesColumn != "" {
+			featuresCol = columnMapping.FeaturesColumn
+		}
 	}
 
 	hallIDIndex := -1
 	capacityIndex := -1
 	groupIndex := -1
+	accommodationIndex := -1
+	featuresIndex := -1
 	for i, col := range header {
 		if col == hallIDCol {
 			hallIDIndex = i
@@ -136,51 +363,98 @@ func ParseHalls(csvData string, columnMapping *ColumnMapping) ([]*Hall, error) {
 		if col == groupCol {
 			groupIndex = i
 		}
+		if col == accommodationCol {
+			accommodationIndex = i
+		}
+		if col == featuresCol {
+			featuresIndex = i
+		}
 	}
 
 	if hallIDIndex == -1 || capacityIndex == -1 {
-		return nil, fmt.Errorf("missing required columns: %s or %s", hallIDCol, capacityCol)
+		return nil, nil, fmt.Errorf("missing required columns: %s or %s", hallIDCol, capacityCol)
 	}
 
 	var halls []*Hall
+	seen := make(map[HallID]int)
+	variants := make(idVariants)
 	for {
-		record, err := csvReader.Read()
+		record, err := rows.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			continue // Skip records with parsing errors
+			diags.readError(err)
+			continue
 		}
+		line := rows.Line()
 
 		if len(record) <= hallIDIndex || len(record) <= capacityIndex {
-			continue // Skip malformed rows
+			diags.add(SeverityError, line, "", "row has %d fields, expected at least %d", len(record), max(hallIDIndex, capacityIndex)+1)
+			continue
 		}
 
 		hallID := HallID(record[hallIDIndex])
 		if hallID == "" {
-			continue // Skip rows with empty hall ID
+			diags.add(SeverityError, line, hallIDCol, "empty hall ID")
+			continue
 		}
 
+		// A capacity is read up to its first non-digit, so "100 seats" and "100.0" are 100;
+		// anything but a plain number is reported so the reading can be checked.
 		var capacity int
 		if _, err := fmt.Sscanf(record[capacityIndex], "%d", &capacity); err != nil || capacity < 0 {
-			continue // Skip rows with invalid capacity
+			diags.add(SeverityError, line, capacityCol, "invalid capacity %q for hall %s", record[capacityIndex], hallID)
+			continue
+		}
+		if _, err := strconv.Atoi(strings.TrimSpace(record[capacityIndex])); err != nil {
+			diags.add(SeverityWarning, line, capacityCol, "capacity %q for hall %s read as %d", record[capacityIndex], hallID, capacity)
+		}
+		if capacity == 0 {
+			diags.add(SeverityWarning, line, capacityCol, "hall %s has no seats", hallID)
+		}
+		if first, ok := seen[hallID]; ok {
+			diags.add(SeverityError, line, hallIDCol, "hall %s is already defined on line %d", hallID, first)
+			continue
 		}
+		seen[hallID] = line
+		variants.check(diags, line, hallIDCol, "hall", string(hallID))
 
 		var group string
 		if groupIndex >= 0 && len(record) > groupIndex {
 			group = record[groupIndex]
 		}
 
+		var accommodation bool
+		if accommodationIndex >= 0 && len(record) > accommodationIndex {
+			accommodation = parseFlag(record[accommodationIndex])
+		}
+
+		var features []string
+		if featuresIndex >= 0 && len(record) > featuresIndex {
+			features = parseFeatures(record[featuresIndex])
+		}
+
 		hall := &Hall{
-			ID:       hallID,
-			Capacity: capacity,
-			Group:    group,
+			ID:            hallID,
+			Capacity:      capacity,
+			Group:         group,
+			Accommodation: accommodation,
+			Features:      features,
 		}
 		halls = append(halls, hall)
 	}
 
-	return halls, nil
-} // ParseAllowedSlots parses the allowed slots CSV data.
+	if strict {
+		if err := diags.strictError(); err != nil {
+			return nil, diags.items, err
+		}
+	}
+	return halls, diags.items, nil
+}
+
+// ParseAllowedSlots parses the allowed slots CSV data.
+// Slot IDs are taken literally; use ResolveAllowedSlots to accept slot patterns.
 func ParseAllowedSlots(csvData string) (map[CourseID]map[SlotID]bool, error) {
 	allowed := make(map[CourseID]map[SlotID]bool)
 	if csvData == "" {
@@ -188,7 +462,7 @@ func ParseAllowedSlots(csvData string) (map[CourseID]map[SlotID]bool, error) {
 	}
 
 	var allowedSlots []*AllowedSlot
-	if err := gocsv.UnmarshalString(csvData, &allowedSlots); err != nil {
+	if err := gocsv.UnmarshalCSV(newCSVRecords(strings.NewReader(csvData)), &allowedSlots); err != nil {
 		return nil, err
 	}
 
@@ -201,14 +475,114 @@ func ParseAllowedSlots(csvData string) (map[CourseID]map[SlotID]bool, error) {
 	return allowed, nil
 }
 
+// ResolveAllowedSlots parses the allowed slots CSV data and resolves each slot_id entry against
+// the given slots. Entries may be exact slot IDs or any pattern accepted by ParseSlotPattern,
+// such as a date, a date range, a weekday, "morning"/"afternoon" or a slot index like "#1".
+// Rows for the same course are combined. A rule that matches no slot is an error.
+func ResolveAllowedSlots(csvData string, slots []*Slot) (map[CourseID]map[SlotID]bool, error) {
+	allowed := make(map[CourseID]map[SlotID]bool)
+	if csvData == "" {
+		return allowed, nil
+	}
+
+	var allowedSlots []*AllowedSlot
+	if err := gocsv.UnmarshalCSV(newCSVRecords(strings.NewReader(csvData)), &allowedSlots); err != nil {
+		return nil, err
+	}
+
+	for _, as := range allowedSlots {
+		pattern, err := ParseSlotPattern(string(as.SlotID))
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed slot for course %s: %w", as.CourseID, err)
+		}
+
+		matched := false
+		for _, slot := range slots {
+			if pattern.Matches(slot) {
+				if _, ok := allowed[as.CourseID]; !ok {
+					allowed[as.CourseID] = make(map[SlotID]bool)
+				}
+				allowed[as.CourseID][slot.ID] = true
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("allowed slot rule %q for course %s matches no slot", pattern, as.CourseID)
+		}
+	}
+	return allowed, nil
+}
+
+// CheckAllowedSlots reports allowed slots CSV rows that name a course without registrations or
+// a slot entry that is invalid or matches no slot. Unknown courses are warnings, since their
+// rows have no effect; bad slot entries are errors, as ResolveAllowedSlots rejects them.
+func CheckAllowedSlots(csvData string, courses map[CourseID]*Course, slots []*Slot) []Diagnostic {
+	if csvData == "" {
+		return nil
+	}
+
+	table, err := newCSVTable("allowed slots", csvData, "course_id", "slot_id")
+	if err != nil {
+		diags := &diagnostics{file: "allowed slots"}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			diags.readError(err)
+		} else {
+			diags.add(SeverityError, 1, "", "%v", err)
+		}
+		return diags.items
+	}
+
+	known := make(map[string]CourseID, len(courses))
+	for courseID := range courses {
+		known[normalizeID(string(courseID))] = courseID
+	}
+
+	for table.next() {
+		courseID := CourseID(table.field("course_id"))
+		if _, ok := courses[courseID]; !ok {
+			if similar, ok := known[normalizeID(string(courseID))]; ok {
+				table.diags.add(SeverityWarning, table.line, "course_id", "course %s has no registrations (did you mean %s?)", courseID, similar)
+			} else {
+				table.diags.add(SeverityWarning, table.line, "course_id", "course %s has no registrations", courseID)
+			}
+		}
+
+		pattern, err := ParseSlotPattern(table.field("slot_id"))
+		if err != nil {
+			table.skip("slot_id", "%v", err)
+			continue
+		}
+		matched := false
+		for _, slot := range slots {
+			if pattern.Matches(slot) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			table.diags.add(SeverityError, table.line, "slot_id", "%q matches no slot", pattern)
+		}
+	}
+	return table.diags.items
+}
+
 // SerializeAssignments serializes the schedule assignments to a CSV string.
 func SerializeAssignments(assignments []*Assignment) (string, error) {
 	var sb strings.Builder
-	writer := csv.NewWriter(&sb)
+	if err := WriteAssignments(&sb, assignments); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// WriteAssignments writes the schedule assignments as CSV to w, one row at a time.
+func WriteAssignments(w io.Writer, assignments []*Assignment) error {
+	writer := csv.NewWriter(w)
 
 	// Write header
 	if err := writer.Write([]string{"course_id", "slot_id", "slot_datetime", "halls", "enrolled_count", "notes"}); err != nil {
-		return "", err
+		return err
 	}
 
 	for _, a := range assignments {
@@ -221,14 +595,10 @@ func SerializeAssignments(assignments []*Assignment) (string, error) {
 			a.Notes,
 		}
 		if err := writer.Write(record); err != nil {
-			return "", err
+			return err
 		}
 	}
 
 	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return "", err
-	}
-
-	return sb.String(), nil
+	return writer.Error()
 }
@@ -146,6 +146,58 @@ s002,MATH201`
 	}
 }
 
+func TestParseRegistrations_WideColumns(t *testing.T) {
+	csvData := `student_number,name,course1,course2,course3
+s001,"Doe, Jane",CS101,MATH201,
+s002,"Roe, Rick",CS101
+s003,"Poe, Edgar",,,
+s004,"Loe, Lou",PHYS101,PHYS101,CS101`
+
+	mapping := &ColumnMapping{
+		StudentIDColumn: "student_number",
+		CourseColumns:   []string{"course1", "course2", "course3"},
+	}
+
+	courses, registrations, diags, err := ParseRegistrationsWithDiagnostics(csvData, mapping, true)
+	if err != nil {
+		t.Fatalf("ParseRegistrationsWithDiagnostics failed: %v", err)
+	}
+	if len(registrations) != 5 {
+		t.Errorf("expected 5 registrations, got %d", len(registrations))
+	}
+	if n := len(courses["CS101"].Enrollments); n != 3 {
+		t.Errorf("expected 3 students in CS101, got %d", n)
+	}
+	if len(diags) != 2 || diags[0].Line != 4 || diags[1].Line != 5 {
+		t.Errorf("expected warnings for the student without courses and the repeated course, got %v", diags)
+	}
+
+	mapping.CourseColumns = append(mapping.CourseColumns, "course4")
+	if _, _, err := ParseRegistrations(csvData, mapping); err == nil {
+		t.Error("expected an error for a missing course column")
+	}
+}
+
+func TestParseRegistrations_DelimitedCourses(t *testing.T) {
+	csvData := `student_id,courses
+s001,CS101; MATH201
+s002,CS101
+s003,`
+
+	mapping := &ColumnMapping{CourseIDColumn: "courses", CourseDelimiter: ";"}
+
+	courses, registrations, err := ParseRegistrations(csvData, mapping)
+	if err != nil {
+		t.Fatalf("ParseRegistrations failed: %v", err)
+	}
+	if len(registrations) != 3 || len(courses) != 2 {
+		t.Errorf("expected 3 registrations in 2 courses, got %d in %d", len(registrations), len(courses))
+	}
+	if courses["MATH201"] == nil {
+		t.Error("expected spaces around the delimiter to be trimmed")
+	}
+}
+
 func TestParseHalls_DefaultColumns(t *testing.T) {
 	// Test that default columns still work when mapping is nil
 	csvData := `hall,capacity,group
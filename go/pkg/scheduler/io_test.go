@@ -1,7 +1,11 @@
 package scheduler
 
 import (
+	"bytes"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 const (
@@ -68,6 +72,25 @@ func TestParseHalls(t *testing.T) {
 	}
 }
 
+func TestParseHalls_Features(t *testing.T) {
+	halls, err := ParseHalls(`room,seats,tags
+Lab1,30,Lab; Accessible
+Main,200,
+`, &ColumnMapping{HallIDColumn: "room", CapacityColumn: "seats", FeaturesColumn: "tags"})
+	if err != nil {
+		t.Fatalf("ParseHalls failed: %v", err)
+	}
+	if len(halls) != 2 {
+		t.Fatalf("expected 2 halls, got %d", len(halls))
+	}
+	if len(halls[0].Features) != 2 || halls[0].Features[0] != "accessible" || halls[0].Features[1] != "lab" {
+		t.Errorf("unexpected features for Lab1: %v", halls[0].Features)
+	}
+	if len(halls[1].Features) != 0 {
+		t.Errorf("expected no features for Main, got %v", halls[1].Features)
+	}
+}
+
 func TestParseAllowedSlots(t *testing.T) {
 	allowed, err := ParseAllowedSlots(validAllowedSlotsCSV)
 	if err != nil {
@@ -82,6 +105,71 @@ func TestParseAllowedSlots(t *testing.T) {
 	}
 }
 
+func TestResolveAllowedSlots(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-10", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	allowed, err := ResolveAllowedSlots(`course_id,slot_id
+c1,2025-01-07T09:00Z#1
+c2,2025-01-08..2025-01-09 morning
+c2,Fri #2
+`, slots)
+	if err != nil {
+		t.Fatalf("ResolveAllowedSlots failed: %v", err)
+	}
+
+	if len(allowed["c1"]) != 1 || !allowed["c1"]["2025-01-07T09:00Z#1"] {
+		t.Errorf("unexpected allowed slots for c1: %v", allowed["c1"])
+	}
+	if len(allowed["c2"]) != 3 || !allowed["c2"]["2025-01-10T14:00Z#2"] {
+		t.Errorf("unexpected allowed slots for c2: %v", allowed["c2"])
+	}
+
+	if _, err := ResolveAllowedSlots("course_id,slot_id\nc1,Saturday\n", slots); err == nil {
+		t.Error("expected an error for a rule that matches no slot")
+	}
+}
+
+func TestCheckAllowedSlots(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-10", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	courses := map[CourseID]*Course{"CS101": {ID: "CS101"}, "MA101": {ID: "MA101"}}
+	diags := CheckAllowedSlots(`course_id,slot_id
+CS101,Mon
+cs101,Tue
+PH101,Wed
+MA101,2025-01-13T09:00Z#1
+`, courses, slots)
+
+	expected := []string{
+		"allowed slots:3: course_id: warning: course cs101 has no registrations (did you mean CS101?)",
+		"allowed slots:4: course_id: warning: course PH101 has no registrations",
+		`allowed slots:5: slot_id: error: "2025-01-13T09:00Z#1" matches no slot`,
+	}
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %v", len(expected), diags)
+	}
+	for i, e := range expected {
+		if got := diags[i].String(); got != e {
+			t.Errorf("diagnostic %d: expected %s, got %s", i, e, got)
+		}
+	}
+}
+
+func TestAllowedSlots_SkipsComments(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-10", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	courses := map[CourseID]*Course{"CS101": {ID: "CS101"}}
+	csvData := "course_id,slot_id\n# CS101 may only sit on Monday\nCS101,Mon\n"
+
+	if diags := CheckAllowedSlots(csvData, courses, slots); len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+	allowed, err := ResolveAllowedSlots(csvData, slots)
+	if err != nil {
+		t.Fatalf("ResolveAllowedSlots failed: %v", err)
+	}
+	if len(allowed) != 1 || len(allowed["CS101"]) != 2 {
+		t.Errorf("expected CS101 to get the two Monday slots, got %v", allowed)
+	}
+}
+
 func TestSerializeAssignments(t *testing.T) {
 	assignments := []*Assignment{
 		{CourseID: "c1", SlotID: "s1", SlotDateTime: "t1", Halls: "h1;h2", EnrolledCount: 10},
@@ -101,3 +189,182 @@ c2,s2,t2,h3,20,a note
 		t.Errorf("unexpected CSV output.\nGot:\n%s\nExpected:\n%s", csv, expected)
 	}
 }
+
+func TestReadRegistrations(t *testing.T) {
+	// A reader that returns one byte at a time makes every record span reads
+	courses, regs, diags, err := ReadRegistrations(iotest.OneByteReader(strings.NewReader(validRegCSV)), nil, false)
+	if err != nil {
+		t.Fatalf("ReadRegistrations failed: %v", err)
+	}
+	wantCourses, wantRegs, wantDiags, _ := ParseRegistrationsWithDiagnostics(validRegCSV, nil, false)
+	if !reflect.DeepEqual(courses, wantCourses) || !reflect.DeepEqual(regs, wantRegs) || !reflect.DeepEqual(diags, wantDiags) {
+		t.Errorf("expected the same result as ParseRegistrationsWithDiagnostics, got %v %v", regs, diags)
+	}
+
+	halls, _, err := ReadHalls(iotest.OneByteReader(strings.NewReader(validHallsCSV)), nil, false)
+	if err != nil || len(halls) != 3 || halls[2].ID != "H3, Big" {
+		t.Errorf("unexpected halls from ReadHalls: %v, %v", halls, err)
+	}
+
+	if _, _, _, err := ReadRegistrations(iotest.ErrReader(iotest.ErrTimeout), nil, false); err != iotest.ErrTimeout {
+		t.Errorf("expected the reader's error, got %v", err)
+	}
+}
+
+func TestWriteAssignments(t *testing.T) {
+	assignments := []*Assignment{
+		{CourseID: "c1", SlotID: "s1", SlotDateTime: "t1", Halls: "h1;h2", EnrolledCount: 10},
+		{CourseID: "c2", SlotID: "s2", SlotDateTime: "t2", Halls: "h3", EnrolledCount: 20, Notes: "a, note"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAssignments(&buf, assignments); err != nil {
+		t.Fatalf("WriteAssignments failed: %v", err)
+	}
+	read, err := ReadSchedule(&buf)
+	if err != nil {
+		t.Fatalf("ReadSchedule failed: %v", err)
+	}
+	if !reflect.DeepEqual(read, assignments) {
+		t.Errorf("expected the assignments back, got %+v", read)
+	}
+}
+
+func TestParseRegistrationsWithDiagnostics(t *testing.T) {
+	csvData := `student_id,course_id
+s1,CS101
+s2,
+,CS101
+s3
+s4,"CS"102"
+s5,MA101 
+`
+	courses, regs, diags, err := ParseRegistrationsWithDiagnostics(csvData, nil, false)
+	if err != nil {
+		t.Fatalf("ParseRegistrationsWithDiagnostics failed: %v", err)
+	}
+	if len(regs) != 3 || len(courses) != 3 {
+		t.Errorf("expected 3 registrations in 3 courses, got %d in %d", len(regs), len(courses))
+	}
+
+	expected := []Diagnostic{
+		{File: "registrations", Line: 3, Column: "course_id", Severity: SeverityError, Message: "empty course ID"},
+		{File: "registrations", Line: 4, Column: "student_id", Severity: SeverityError, Message: "empty student ID"},
+		{File: "registrations", Line: 5, Severity: SeverityError, Message: "row has 1 fields, expected at least 2"},
+		{File: "registrations", Line: 7, Column: "course_id", Severity: SeverityWarning, Message: `course ID "MA101 " has surrounding spaces`},
+	}
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %d: %v", len(expected), len(diags), diags)
+	}
+	for i, e := range expected {
+		if diags[i] != e {
+			t.Errorf("diagnostic %d: expected %v, got %v", i, e, diags[i])
+		}
+	}
+
+	if _, _, _, err := ParseRegistrationsWithDiagnostics(csvData, nil, true); err == nil {
+		t.Error("expected strict mode to fail on skipped rows")
+	}
+	if _, _, _, err := ParseRegistrationsWithDiagnostics("student_id,course_id\ns1,CS101 \n", nil, true); err != nil {
+		t.Errorf("expected strict mode to accept warnings, got %v", err)
+	}
+}
+
+func TestParseRegistrations_Duplicates(t *testing.T) {
+	csvData := `student_id,course_id
+s1,CS101
+s2,CS101
+s1,CS101
+s3,cs101
+s4,CS 101
+S1,MA101
+`
+	courses, regs, diags, err := ParseRegistrationsWithDiagnostics(csvData, nil, true)
+	if err != nil {
+		t.Fatalf("expected duplicates to be warnings, got %v", err)
+	}
+	if len(regs) != 5 {
+		t.Errorf("expected 5 registrations, got %d", len(regs))
+	}
+	if n := len(courses["CS101"].Enrollments); n != 2 {
+		t.Errorf("expected CS101 to have 2 enrollments, got %d", n)
+	}
+
+	expected := []string{
+		"registrations:4: warning: student s1 is registered for course CS101 again (first on line 2); the duplicate is ignored",
+		`registrations:5: course_id: warning: course ID "cs101" differs from "CS101" only in case or spacing`,
+		`registrations:6: course_id: warning: course ID "CS 101" differs from "CS101" only in case or spacing`,
+		`registrations:7: student_id: warning: student ID "S1" differs from "s1" only in case or spacing`,
+	}
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %v", len(expected), diags)
+	}
+	for i, e := range expected {
+		if got := diags[i].String(); got != e {
+			t.Errorf("diagnostic %d: expected %s, got %s", i, e, got)
+		}
+	}
+}
+
+func TestParseHallsWithDiagnostics(t *testing.T) {
+	csvData := `hall,capacity
+H1,100
+H2,O100
+H3,-5
+H4,0
+H1,50
+`
+	halls, diags, err := ParseHallsWithDiagnostics(csvData, nil, false)
+	if err != nil {
+		t.Fatalf("ParseHallsWithDiagnostics failed: %v", err)
+	}
+	if len(halls) != 2 || halls[0].Capacity != 100 {
+		t.Errorf("expected 2 halls keeping the first H1, got %v", halls)
+	}
+	if len(diags) != 4 {
+		t.Fatalf("expected 4 diagnostics, got %v", diags)
+	}
+	if diags[0].Line != 3 || diags[0].Column != "capacity" || diags[0].Severity != SeverityError {
+		t.Errorf("unexpected diagnostic for the typo in H2: %v", diags[0])
+	}
+	if got := diags[3].String(); got != "halls:6: hall: error: hall H1 is already defined on line 2" {
+		t.Errorf("unexpected duplicate hall diagnostic: %s", got)
+	}
+
+	_, _, err = ParseHallsWithDiagnostics(csvData, nil, true)
+	if err == nil || !strings.Contains(err.Error(), "3 rows skipped, first at halls:3: capacity") {
+		t.Errorf("expected a strict mode error naming the first skipped row, got %v", err)
+	}
+}
+
+func TestParseHallsWithDiagnostics_LenientCapacity(t *testing.T) {
+	csvData := `hall,capacity
+H1,100 seats
+H2,100.0
+H3, 80
+`
+	halls, diags, err := ParseHallsWithDiagnostics(csvData, nil, true)
+	if err != nil {
+		t.Fatalf("ParseHallsWithDiagnostics failed: %v", err)
+	}
+	if len(halls) != 3 {
+		t.Fatalf("expected 3 halls, got %v", halls)
+	}
+	for i, want := range []int{100, 100, 80} {
+		if halls[i].Capacity != want {
+			t.Errorf("hall %s: expected capacity %d, got %d", halls[i].ID, want, halls[i].Capacity)
+		}
+	}
+	expected := []string{
+		`halls:2: capacity: warning: capacity "100 seats" for hall H1 read as 100`,
+		`halls:3: capacity: warning: capacity "100.0" for hall H2 read as 100`,
+	}
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %v", len(expected), diags)
+	}
+	for i, e := range expected {
+		if got := diags[i].String(); got != e {
+			t.Errorf("diagnostic %d: expected %s, got %s", i, e, got)
+		}
+	}
+}
@@ -24,7 +24,11 @@ type Course struct {
 type Hall struct {
 	ID       HallID `csv:"hall"`
 	Capacity int    `csv:"capacity"`
-	Group    string `csv:"group,omitempty"`
+	Group    string `csv:"group,omitempty"` // Campus or site; used for travel times between campuses
+	// Accommodation marks a designated room for students with extra time or a separate-room need.
+	Accommodation bool `csv:"accommodation,omitempty"`
+	// Features lists the hall's lower-case tags, such as "lab" or "accessible".
+	Features []string `csv:"-"`
 }
 
 // Slot represents a time slot for an exam.
@@ -44,6 +48,11 @@ type Assignment struct {
 	Halls         string   `csv:"halls"` // Semicolon-separated list of HallIDs
 	EnrolledCount int      `csv:"enrolled_count"`
 	Notes         string   `csv:"notes,omitempty"`
+	// AccommodatedCount is the number of enrolled students who need a designated room.
+	// It is used for hall allocation only and is not serialized.
+	AccommodatedCount int `csv:"-"`
+	// RequiredFeatures lists the hall features the course needs; every hall used must have them all.
+	RequiredFeatures []string `csv:"-"`
 }
 
 // Registration represents a single student registration for a course.
@@ -62,7 +71,17 @@ type AllowedSlot struct {
 type ColumnMapping struct {
 	StudentIDColumn string `json:"studentIdColumn"`
 	CourseIDColumn  string `json:"courseIdColumn"`
+	// CourseColumns are the course columns of a wide registrations file with one row per
+	// student, e.g. course1..course8; empty cells are skipped. They replace CourseIDColumn.
+	CourseColumns []string `json:"courseColumns,omitempty"`
+	// CourseDelimiter splits course cells listing several courses, e.g. ";" for a courses
+	// column holding "CS101;MA101". Setting it also makes the file wide.
+	CourseDelimiter string `json:"courseDelimiter,omitempty"`
 	HallIDColumn    string `json:"hallIdColumn"`
 	CapacityColumn  string `json:"capacityColumn"`
 	GroupColumn     string `json:"groupColumn"`
+	// AccommodationColumn flags designated rooms for accommodated students (default: "accommodation")
+	AccommodationColumn string `json:"accommodationColumn"`
+	// FeaturesColumn lists semicolon-separated hall features (default: "features")
+	FeaturesColumn string `json:"featuresColumn"`
 }
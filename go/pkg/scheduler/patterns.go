@@ -0,0 +1,169 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SlotPattern selects slots either by exact slot ID or by a combination of terms, all of which
+// must match. Terms are separated by spaces and may be:
+//   - a date: 2025-05-12
+//   - an inclusive date range: 2025-05-12..2025-05-16
+//   - a weekday: mon, Monday
+//   - a part of the day: morning (before 12:00), afternoon (12:00-17:00), evening (from 17:00)
+//   - a start time: 14:00
+//   - a slot index within the day, counting from 1: #2
+//
+// For example "Fri afternoon", "2025-05-12 09:00" or "2025-05-12..2025-05-16 #1".
+// Anything else is treated as a slot ID.
+type SlotPattern struct {
+	raw    string
+	slotID SlotID
+	terms  []func(*Slot) bool
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseSlotPattern parses a slot pattern.
+func ParseSlotPattern(s string) (SlotPattern, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return SlotPattern{}, fmt.Errorf("empty slot pattern")
+	}
+
+	var terms []func(*Slot) bool
+	for _, field := range strings.Fields(s) {
+		term, ok := parseSlotTerm(field)
+		if !ok {
+			return SlotPattern{raw: s, slotID: SlotID(s)}, nil
+		}
+		terms = append(terms, term)
+	}
+	return SlotPattern{raw: s, terms: terms}, nil
+}
+
+// parseSlotTerm parses a single pattern term, reporting false if it is not a known term.
+func parseSlotTerm(field string) (func(*Slot) bool, bool) {
+	lower := strings.ToLower(field)
+
+	if _, err := time.Parse("2006-01-02", field); err == nil {
+		return func(slot *Slot) bool { return slot.Start.Format("2006-01-02") == field }, true
+	}
+	if from, to, ok := strings.Cut(field, ".."); ok {
+		if _, err := time.Parse("2006-01-02", from); err != nil {
+			return nil, false
+		}
+		if _, err := time.Parse("2006-01-02", to); err != nil {
+			return nil, false
+		}
+		return func(slot *Slot) bool {
+			date := slot.Start.Format("2006-01-02")
+			return date >= from && date <= to
+		}, true
+	}
+	if index, ok := strings.CutPrefix(field, "#"); ok {
+		n, err := strconv.Atoi(index)
+		if err != nil || n < 1 {
+			return nil, false
+		}
+		return func(slot *Slot) bool { return slot.IndexInDay == n-1 }, true
+	}
+	if weekday, ok := weekdayNames[lower]; ok {
+		return func(slot *Slot) bool { return slot.Start.Weekday() == weekday }, true
+	}
+	switch lower {
+	case "morning":
+		return func(slot *Slot) bool { return slot.Start.Hour() < 12 }, true
+	case "afternoon":
+		return func(slot *Slot) bool { return slot.Start.Hour() >= 12 && slot.Start.Hour() < 17 }, true
+	case "evening":
+		return func(slot *Slot) bool { return slot.Start.Hour() >= 17 }, true
+	}
+	if t, err := time.Parse("15:04", field); err == nil {
+		return func(slot *Slot) bool { return slot.Start.Hour() == t.Hour() && slot.Start.Minute() == t.Minute() }, true
+	}
+	return nil, false
+}
+
+// ParseSlotPatterns parses a semicolon-separated list of slot patterns.
+func ParseSlotPatterns(s string) ([]SlotPattern, error) {
+	var patterns []SlotPattern
+	for _, part := range splitList(s) {
+		p, err := ParseSlotPattern(part)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// Matches reports whether the slot is selected by the pattern.
+// Dates and times are compared in the slot's own time zone.
+func (p SlotPattern) Matches(slot *Slot) bool {
+	if p.slotID != "" {
+		return slot.ID == p.slotID
+	}
+	for _, term := range p.terms {
+		if !term(slot) {
+			return false
+		}
+	}
+	return len(p.terms) > 0
+}
+
+// String returns the pattern as it was written.
+func (p SlotPattern) String() string {
+	return p.raw
+}
+
+// matchesAny reports whether any of the patterns selects the slot.
+func matchesAny(patterns []SlotPattern, slot *Slot) bool {
+	for _, p := range patterns {
+		if p.Matches(slot) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSlotPatterns warns about rows of a CSV whose slot pattern column selects none of the slots.
+// Rows that cannot be read or whose pattern is invalid are left to the file's parser.
+func checkSlotPatterns(file, csvData, column string, slots []*Slot) []Diagnostic {
+	if csvData == "" {
+		return nil
+	}
+	table, err := newCSVTable(file, csvData, column)
+	if err != nil {
+		return nil
+	}
+
+	diags := &diagnostics{file: file}
+	for table.next() {
+		pattern, err := ParseSlotPattern(table.field(column))
+		if err != nil {
+			continue
+		}
+		matched := false
+		for _, slot := range slots {
+			if pattern.Matches(slot) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			diags.add(SeverityWarning, table.line, column, "%q matches no slot, so the row has no effect", pattern)
+		}
+	}
+	return diags.items
+}
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"testing"
+)
+
+func TestSlotPattern_Matches(t *testing.T) {
+	// 2025-01-06 is a Monday, 2025-01-10 a Friday.
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-10", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	cases := map[string]int{
+		"2025-01-06T09:00Z#1":       1,
+		"2025-01-07":                2,
+		"fri":                       2,
+		"Friday afternoon":          1,
+		"morning":                   5,
+		"14:00":                     5,
+		"Mon 14:00":                 1,
+		"2025-01-07..2025-01-09":    6,
+		"#2":                        5,
+		"2025-01-06..2025-01-07 #1": 2,
+		"2025-01-08 evening":        0,
+		"no-such-slot":              0,
+	}
+
+	for pattern, want := range cases {
+		p, err := ParseSlotPattern(pattern)
+		if err != nil {
+			t.Fatalf("ParseSlotPattern(%q) failed: %v", pattern, err)
+		}
+		got := 0
+		for _, s := range slots {
+			if p.Matches(s) {
+				got++
+			}
+		}
+		if got != want {
+			t.Errorf("pattern %q matched %d slots, want %d", pattern, got, want)
+		}
+	}
+}
@@ -4,11 +4,37 @@ import "time"
 
 // PenaltyConfig defines the weights for different penalty components.
 type PenaltyConfig struct {
-	StudentProximityWeight float64
-	MinGapViolationWeight  float64
+	StudentProximityWeight float64 `json:"studentProximityWeight"`
+	MinGapViolationWeight  float64 `json:"minGapViolationWeight"`
+	// CampusTravelWeight is charged for each student who cannot travel between the campuses of
+	// consecutive exams in time. It is applied after hall allocation.
+	CampusTravelWeight float64 `json:"campusTravelWeight"`
 	// Add other penalty weights here
 }
 
+// PenaltyBreakdown splits a schedule's penalty into its components.
+type PenaltyBreakdown struct {
+	StudentProximity PenaltyTerm `json:"studentProximity"` // Pairs of a student's exams on the same day
+	MinGapViolation  PenaltyTerm `json:"minGapViolation"`  // Pairs of a student's exams closer than the minimum gap
+	CampusTravel     PenaltyTerm `json:"campusTravel"`     // Students who cannot reach the campus of their next exam
+}
+
+// PenaltyTerm is one component of a penalty: how often it occurs, its weight and their product.
+type PenaltyTerm struct {
+	Count   int     `json:"count"`
+	Weight  float64 `json:"weight"`
+	Penalty float64 `json:"penalty"`
+}
+
+func newPenaltyTerm(count int, weight float64) PenaltyTerm {
+	return PenaltyTerm{Count: count, Weight: weight, Penalty: float64(count) * weight}
+}
+
+// Total returns the sum of the components.
+func (b *PenaltyBreakdown) Total() float64 {
+	return b.StudentProximity.Penalty + b.MinGapViolation.Penalty + b.CampusTravel.Penalty
+}
+
 // CalculatePenalty calculates the total penalty for a given schedule.
 func CalculatePenalty(
 	schedule map[CourseID]int,
@@ -18,7 +44,20 @@ func CalculatePenalty(
 	minGapMinutes int,
 	config PenaltyConfig,
 ) float64 {
-	var totalPenalty float64
+	breakdown := calculatePenaltyBreakdown(schedule, courses, slots, minGapMinutes, config)
+	return breakdown.Total()
+}
+
+// calculatePenaltyBreakdown counts the student proximity and minimum gap penalties of a schedule.
+// Campus travel is left at zero as it depends on the halls.
+func calculatePenaltyBreakdown(
+	schedule map[CourseID]int,
+	courses map[CourseID]*Course,
+	slots []*Slot,
+	minGapMinutes int,
+	config PenaltyConfig,
+) *PenaltyBreakdown {
+	var sameDay, minGapViolations int
 
 	studentSchedules := make(map[StudentID][]time.Time)
 
@@ -43,17 +82,21 @@ func CalculatePenalty(
 
 					// Proximity penalty (e.g., exams on the same day)
 					if exams[i].Day() == exams[j].Day() {
-						totalPenalty += config.StudentProximityWeight
+						sameDay++
 					}
 
 					// Minimum gap violation
 					if minGapMinutes > 0 && gap < minGapDuration {
-						totalPenalty += config.MinGapViolationWeight
+						minGapViolations++
 					}
 				}
 			}
 		}
 	}
 
-	return totalPenalty
+	return &PenaltyBreakdown{
+		StudentProximity: newPenaltyTerm(sameDay, config.StudentProximityWeight),
+		MinGapViolation:  newPenaltyTerm(minGapViolations, config.MinGapViolationWeight),
+		CampusTravel:     newPenaltyTerm(0, config.CampusTravelWeight),
+	}
 }
@@ -0,0 +1,237 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RelationKind is the type of a constraint between two courses.
+type RelationKind string
+
+const (
+	RelationSameSlot      RelationKind = "same_slot"      // Both exams in the same slot
+	RelationDifferentSlot RelationKind = "different_slot" // Exams in different slots
+	RelationSameDay       RelationKind = "same_day"       // Exams on the same day
+	RelationNotSameDay    RelationKind = "not_same_day"   // Exams on different days
+	RelationPrecedes      RelationKind = "precedes"       // A ends at least MinGapMinutes before B starts
+)
+
+// CourseRelation is a hard constraint between two courses.
+type CourseRelation struct {
+	A             CourseID
+	Kind          RelationKind
+	B             CourseID
+	MinGapMinutes int // Only used by RelationPrecedes
+}
+
+// ParseCourseRelations parses the course relations CSV data.
+// Required columns are course_a, relation and course_b; min_gap (minutes) is optional.
+// Relation names are case-insensitive and may use hyphens or spaces instead of underscores;
+// "follows" is accepted as the reverse of "precedes". Rows that cannot be used are skipped; see
+// ParseCourseRelationsWithDiagnostics to find out which.
+func ParseCourseRelations(csvData string) ([]CourseRelation, error) {
+	relations, _, err := ParseCourseRelationsWithDiagnostics(csvData, false)
+	return relations, err
+}
+
+// ParseCourseRelationsWithDiagnostics parses the course relations CSV data like
+// ParseCourseRelations and also reports every skipped row. In strict mode any skipped row is an error.
+func ParseCourseRelationsWithDiagnostics(csvData string, strict bool) ([]CourseRelation, []Diagnostic, error) {
+	if csvData == "" {
+		return nil, nil, nil
+	}
+
+	table, err := newCSVTable("course relations", csvData, "course_a", "relation", "course_b")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var relations []CourseRelation
+	for table.next() {
+		rel := CourseRelation{A: CourseID(table.field("course_a")), B: CourseID(table.field("course_b"))}
+		if rel.A == "" {
+			table.skip("course_a", "empty course ID")
+			continue
+		}
+		if rel.B == "" {
+			table.skip("course_b", "empty course ID")
+			continue
+		}
+
+		kind := strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToLower(table.field("relation")))
+		switch RelationKind(kind) {
+		case RelationSameSlot, RelationDifferentSlot, RelationSameDay, RelationNotSameDay, RelationPrecedes:
+			rel.Kind = RelationKind(kind)
+		case "follows":
+			rel.Kind = RelationPrecedes
+			rel.A, rel.B = rel.B, rel.A
+		default:
+			return nil, nil, fmt.Errorf("unknown relation %q between %s and %s", table.field("relation"), rel.A, rel.B)
+		}
+
+		if v := table.field("min_gap"); v != "" {
+			gap, err := strconv.Atoi(v)
+			if err != nil || gap < 0 {
+				return nil, nil, fmt.Errorf("invalid min_gap %q between %s and %s", v, rel.A, rel.B)
+			}
+			rel.MinGapMinutes = gap
+		}
+		relations = append(relations, rel)
+	}
+
+	diagnostics, err := table.result(strict)
+	if err != nil {
+		return nil, diagnostics, err
+	}
+	return relations, diagnostics, nil
+}
+
+// Satisfied reports whether placing A in slot a and B in slot b respects the relation.
+func (r CourseRelation) Satisfied(a, b *Slot) bool {
+	switch r.Kind {
+	case RelationSameSlot:
+		return a.ID == b.ID
+	case RelationDifferentSlot:
+		return a.ID != b.ID
+	case RelationSameDay:
+		return a.DayIndex == b.DayIndex
+	case RelationNotSameDay:
+		return a.DayIndex != b.DayIndex
+	case RelationPrecedes:
+		return !b.Start.Before(a.End.Add(time.Duration(r.MinGapMinutes) * time.Minute))
+	}
+	return true
+}
+
+// String describes the relation for reports.
+func (r CourseRelation) String() string {
+	if r.Kind == RelationPrecedes && r.MinGapMinutes > 0 {
+		return fmt.Sprintf("%s precedes %s by at least %d minutes", r.A, r.B, r.MinGapMinutes)
+	}
+	return fmt.Sprintf("%s %s %s", r.A, strings.ReplaceAll(string(r.Kind), "_", " "), r.B)
+}
+
+// relationIndex lists, for each course, the relations it takes part in.
+type relationIndex map[CourseID][]CourseRelation
+
+func newRelationIndex(relations []CourseRelation) relationIndex {
+	idx := make(relationIndex)
+	for _, r := range relations {
+		idx[r.A] = append(idx[r.A], r)
+		if r.B != r.A {
+			idx[r.B] = append(idx[r.B], r)
+		}
+	}
+	return idx
+}
+
+// allows reports whether placing the course in slot respects every relation with an already placed course.
+// placed returns the slot of a course, or false if it is not placed yet.
+func (idx relationIndex) allows(courseID CourseID, slot *Slot, placed func(CourseID) (*Slot, bool)) bool {
+	for _, r := range idx[courseID] {
+		if r.A == courseID {
+			if other, ok := placed(r.B); ok && !r.Satisfied(slot, other) {
+				return false
+			}
+		} else if other, ok := placed(r.A); ok && !r.Satisfied(other, slot) {
+			return false
+		}
+	}
+	return true
+}
+
+// narrowSlots removes from the available slots of each related course those that leave its partner
+// no slot satisfying the relation, until nothing changes. This keeps a greedy coloring from placing
+// one side of a relation where the other can no longer follow, such as B in the first slot when A
+// must precede it. It returns a course left without slots, or false if every course still has one.
+// Relations naming courses outside the graph are ignored.
+func narrowSlots(relations []CourseRelation, graph *ConflictGraph, slots []*Slot, available []map[int]bool) (CourseID, bool) {
+	// revise removes slots of course i that no slot of course j supports; forward is false when
+	// i is course B of the relation.
+	revise := func(r CourseRelation, i, j int, forward bool) bool {
+		changed := false
+		for a := range available[i] {
+			supported := false
+			for b := range available[j] {
+				if (forward && r.Satisfied(slots[a], slots[b])) || (!forward && r.Satisfied(slots[b], slots[a])) {
+					supported = true
+					break
+				}
+			}
+			if !supported {
+				delete(available[i], a)
+				changed = true
+			}
+		}
+		return changed
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, r := range relations {
+			i, okA := graph.CourseIndex[r.A]
+			j, okB := graph.CourseIndex[r.B]
+			if !okA || !okB || i == j {
+				continue
+			}
+			if revise(r, i, j, true) {
+				changed = true
+			}
+			if revise(r, j, i, false) {
+				changed = true
+			}
+			if len(available[i]) == 0 {
+				return r.A, true
+			}
+			if len(available[j]) == 0 {
+				return r.B, true
+			}
+		}
+	}
+	return "", false
+}
+
+// sameSlotGroups merges courses linked by same_slot relations into groups, keyed by every member.
+// Courses outside any such relation are not in the map.
+func sameSlotGroups(relations []CourseRelation) map[CourseID][]CourseID {
+	parent := make(map[CourseID]CourseID)
+	var find func(CourseID) CourseID
+	find = func(c CourseID) CourseID {
+		if p, ok := parent[c]; ok && p != c {
+			root := find(p)
+			parent[c] = root
+			return root
+		}
+		parent[c] = c
+		return c
+	}
+
+	for _, r := range relations {
+		if r.Kind == RelationSameSlot {
+			ra, rb := find(r.A), find(r.B)
+			if ra != rb {
+				parent[rb] = ra
+			}
+		}
+	}
+
+	members := make(map[CourseID][]CourseID)
+	for c := range parent {
+		root := find(c)
+		members[root] = append(members[root], c)
+	}
+	groups := make(map[CourseID][]CourseID)
+	for _, group := range members {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool { return group[i] < group[j] })
+		for _, c := range group {
+			groups[c] = group
+		}
+	}
+	return groups
+}
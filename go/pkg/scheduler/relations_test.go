@@ -0,0 +1,150 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCourseRelations(t *testing.T) {
+	relations, err := ParseCourseRelations(`course_a,relation,course_b,min_gap
+MATH101,same slot,MATH101H,
+PHYS101,precedes,PHYS201,1440
+CHEMLAB,Not-Same-Day,CHEM,
+PHYS301,follows,PHYS201,
+`)
+	if err != nil {
+		t.Fatalf("ParseCourseRelations failed: %v", err)
+	}
+	if len(relations) != 4 {
+		t.Fatalf("expected 4 relations, got %d", len(relations))
+	}
+	if relations[0].Kind != RelationSameSlot || relations[2].Kind != RelationNotSameDay {
+		t.Errorf("relation names were not normalized: %+v", relations)
+	}
+	if relations[1].MinGapMinutes != 1440 {
+		t.Errorf("expected min gap 1440, got %d", relations[1].MinGapMinutes)
+	}
+	if relations[3].Kind != RelationPrecedes || relations[3].A != "PHYS201" || relations[3].B != "PHYS301" {
+		t.Errorf("follows was not reversed into precedes: %+v", relations[3])
+	}
+
+	if _, err := ParseCourseRelations("course_a,relation,course_b\nA,sometimes,B\n"); err == nil {
+		t.Error("expected an error for an unknown relation")
+	}
+}
+
+func TestDSATUR_WithRelations(t *testing.T) {
+	courses := map[CourseID]*Course{
+		"MATH101":  {ID: "MATH101", Enrollments: []StudentID{"s1"}},
+		"MATH101H": {ID: "MATH101H", Enrollments: []StudentID{"s2"}},
+		"PHYS101":  {ID: "PHYS101", Enrollments: []StudentID{"s1"}},
+		"PHYS201":  {ID: "PHYS201", Enrollments: []StudentID{"s3"}},
+		"CHEM":     {ID: "CHEM", Enrollments: []StudentID{"s4"}},
+		"CHEMLAB":  {ID: "CHEMLAB", Enrollments: []StudentID{"s5"}},
+	}
+	relations := []CourseRelation{
+		{A: "MATH101", Kind: RelationSameSlot, B: "MATH101H"},
+		{A: "PHYS101", Kind: RelationPrecedes, B: "PHYS201", MinGapMinutes: 60},
+		{A: "CHEM", Kind: RelationNotSameDay, B: "CHEMLAB"},
+	}
+	graph := NewConflictGraph(courses)
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-08", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+
+	coloring, err := DSATURWithRelations(graph, slots, make(map[CourseID]map[SlotID]bool), relations, 1)
+	if err != nil {
+		t.Fatalf("DSATUR failed: %v", err)
+	}
+	for _, r := range relations {
+		if !r.Satisfied(slots[coloring[r.A]], slots[coloring[r.B]]) {
+			t.Errorf("relation violated: %s", r)
+		}
+	}
+}
+
+func TestDSATUR_RelationOrder(t *testing.T) {
+	// B has the highest degree, so it is colored before A and would take the first slot
+	courses := map[CourseID]*Course{
+		"A": {ID: "A", Enrollments: []StudentID{"s1"}},
+		"B": {ID: "B", Enrollments: []StudentID{"s2", "s3"}},
+		"C": {ID: "C", Enrollments: []StudentID{"s2"}},
+		"D": {ID: "D", Enrollments: []StudentID{"s3"}},
+	}
+	graph := NewConflictGraph(courses)
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-08", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+
+	precedes := []CourseRelation{{A: "A", Kind: RelationPrecedes, B: "B"}}
+	for seed := int64(1); seed <= 20; seed++ {
+		coloring, err := DSATURWithRelations(graph, slots, make(map[CourseID]map[SlotID]bool), precedes, seed)
+		if err != nil {
+			t.Fatalf("seed %d: DSATUR failed on precedes: %v", seed, err)
+		}
+		if !precedes[0].Satisfied(slots[coloring["A"]], slots[coloring["B"]]) {
+			t.Errorf("seed %d: relation violated: %s", seed, precedes[0])
+		}
+	}
+
+	// A may only sit on the last day, so B must be kept off the first
+	sameDay := []CourseRelation{{A: "A", Kind: RelationSameDay, B: "B"}}
+	allowed := map[CourseID]map[SlotID]bool{"A": {slots[4].ID: true, slots[5].ID: true}}
+	coloring, err := DSATURWithRelations(graph, slots, allowed, sameDay, 1)
+	if err != nil {
+		t.Fatalf("DSATUR failed on same_day: %v", err)
+	}
+	if !sameDay[0].Satisfied(slots[coloring["A"]], slots[coloring["B"]]) {
+		t.Errorf("relation violated: %s", sameDay[0])
+	}
+
+	impossible := []CourseRelation{{A: "A", Kind: RelationPrecedes, B: "B"}, {A: "B", Kind: RelationPrecedes, B: "A"}}
+	if _, err := DSATURWithRelations(graph, slots, make(map[CourseID]map[SlotID]bool), impossible, 1); err == nil {
+		t.Error("DSATUR should fail when two courses must precede each other")
+	}
+}
+
+func TestDSATUR_SameSlotWithSharedStudents(t *testing.T) {
+	courses := map[CourseID]*Course{
+		"c1": {ID: "c1", Enrollments: []StudentID{"s1"}},
+		"c2": {ID: "c2", Enrollments: []StudentID{"s1"}},
+	}
+	graph := NewConflictGraph(courses)
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-06", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+
+	_, err := DSATURWithRelations(graph, slots, make(map[CourseID]map[SlotID]bool), []CourseRelation{{A: "c1", Kind: RelationSameSlot, B: "c2"}}, 1)
+	if err == nil {
+		t.Fatal("DSATUR should fail when same-slot courses share students")
+	}
+}
+
+func TestParseCourseRelationsWithDiagnostics(t *testing.T) {
+	csvData := `course_a,relation,course_b
+MATH101,same slot,MATH101H
+,precedes,PHYS201
+CHEM,not same day
+PHYS101,"precedes,PHYS201
+`
+	relations, diags, err := ParseCourseRelationsWithDiagnostics(csvData, false)
+	if err != nil {
+		t.Fatalf("ParseCourseRelationsWithDiagnostics failed: %v", err)
+	}
+	if len(relations) != 1 {
+		t.Errorf("expected 1 relation, got %v", relations)
+	}
+	expected := []string{
+		"course relations:3: course_a: error: empty course ID",
+		"course relations:4: error: row has 2 fields, expected at least 3",
+	}
+	if len(diags) != 3 {
+		t.Fatalf("expected 3 diagnostics, got %v", diags)
+	}
+	for i, e := range expected {
+		if got := diags[i].String(); got != e {
+			t.Errorf("diagnostic %d: expected %s, got %s", i, e, got)
+		}
+	}
+	if diags[2].Severity != SeverityError || diags[2].Line != 5 {
+		t.Errorf("expected an error for the unterminated quote on line 5, got %v", diags[2])
+	}
+
+	if _, _, err := ParseCourseRelationsWithDiagnostics(csvData, true); err == nil || !strings.Contains(err.Error(), "3 rows skipped") {
+		t.Errorf("expected a strict mode error, got %v", err)
+	}
+}
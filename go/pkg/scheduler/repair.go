@@ -0,0 +1,317 @@
+package scheduler
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ParseSchedule parses a schedule CSV as produced by SerializeAssignments.
+func ParseSchedule(csvData string) ([]*Assignment, error) {
+	return parseScheduleCSV(csvData)
+}
+
+// ReadSchedule reads a schedule CSV like ParseSchedule, one row at a time.
+func ReadSchedule(r io.Reader) ([]*Assignment, error) {
+	return readScheduleCSV(r)
+}
+
+// RepairSchedule adapts a published schedule to updated registrations while moving as few exams as possible.
+// Courses that now clash or break a course relation are resolved by moving a minimal set of them (chosen greedily by clash count,
+// then by fewest enrolled students) to the feasible slot that adds the least penalty. New courses are
+// placed the same way and dropped courses are removed. Courses that stay put keep their halls unless
+// their new enrollment no longer fits. It returns the repaired schedule and the list of changes.
+func RepairSchedule(
+	published []*Assignment,
+	courses map[CourseID]*Course,
+	halls []*Hall,
+	slots []*Slot,
+	allowedSlots map[CourseID]map[SlotID]bool,
+	graph *ConflictGraph,
+	minGapMinutes int,
+	penaltyConfig PenaltyConfig,
+) (*ScheduleResult, []CourseChange, error) {
+	return RepairScheduleWithConstraints(published, courses, halls, slots, allowedSlots, graph, minGapMinutes, penaltyConfig, nil)
+}
+
+// RepairScheduleWithConstraints runs RepairSchedule with the given constraints, which may be nil.
+// With course aliases, courses, allowedSlots and graph are those of the merged exams (see
+// MergeAliases) and the published rows of aliased courses are folded into their exam, so the
+// repaired schedule lists exams; ExpandAliases turns it back into course codes.
+func RepairScheduleWithConstraints(
+	published []*Assignment,
+	courses map[CourseID]*Course,
+	halls []*Hall,
+	slots []*Slot,
+	allowedSlots map[CourseID]map[SlotID]bool,
+	graph *ConflictGraph,
+	minGapMinutes int,
+	penaltyConfig PenaltyConfig,
+	constraints *Constraints,
+) (*ScheduleResult, []CourseChange, error) {
+	allowedSlots, err := RestrictAllowedSlots(allowedSlots, courses, slots, constraints)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, published = mergeAliasedAssignments(&ValidationReport{}, nil, published, constraints.aliases())
+
+	slotIndex := make(map[SlotID]int, len(slots))
+	for i, s := range slots {
+		slotIndex[s.ID] = i
+	}
+	previous := make(map[CourseID]*Assignment, len(published))
+	for _, a := range published {
+		previous[a.CourseID] = a
+	}
+
+	// Keep every published course whose slot still exists and is still allowed.
+	coloring := make(map[CourseID]int, len(courses))
+	for courseID, a := range previous {
+		if _, ok := courses[courseID]; !ok {
+			continue
+		}
+		idx, ok := slotIndex[a.SlotID]
+		if !ok {
+			continue
+		}
+		if allowed, ok := allowedSlots[courseID]; ok && len(allowed) > 0 && !allowed[a.SlotID] {
+			continue
+		}
+		coloring[courseID] = idx
+	}
+
+	relIndex := newRelationIndex(constraints.relations())
+	placed := func(courseID CourseID) (*Slot, bool) {
+		if slotIdx, ok := coloring[courseID]; ok {
+			return slots[slotIdx], true
+		}
+		return nil, false
+	}
+
+	// Unschedule a small set of courses that covers every clash and broken relation.
+	for {
+		clashes := make(map[CourseID]int)
+		for i := range graph.Courses {
+			si, ok := coloring[graph.Courses[i]]
+			if !ok {
+				continue
+			}
+			for j := i + 1; j < len(graph.Courses); j++ {
+				if graph.AdjMatrix[i][j] == 0 {
+					continue
+				}
+				if sj, ok := coloring[graph.Courses[j]]; ok && si == sj {
+					clashes[graph.Courses[i]]++
+					clashes[graph.Courses[j]]++
+				}
+			}
+		}
+		for _, r := range constraints.relations() {
+			slotA, okA := placed(r.A)
+			slotB, okB := placed(r.B)
+			if okA && okB && !r.Satisfied(slotA, slotB) {
+				clashes[r.A]++
+				clashes[r.B]++
+			}
+		}
+		if len(clashes) == 0 {
+			break
+		}
+
+		var worst CourseID
+		for courseID, n := range clashes {
+			if worst == "" || n > clashes[worst] ||
+				(n == clashes[worst] && len(courses[courseID].Enrollments) < len(courses[worst].Enrollments)) ||
+				(n == clashes[worst] && len(courses[courseID].Enrollments) == len(courses[worst].Enrollments) && courseID < worst) {
+				worst = courseID
+			}
+		}
+		delete(coloring, worst)
+	}
+
+	// Place moved and new courses, most constrained first.
+	var toPlace []int
+	for i, courseID := range graph.Courses {
+		if _, ok := coloring[courseID]; !ok {
+			toPlace = append(toPlace, i)
+		}
+	}
+	sort.Slice(toPlace, func(i, j int) bool {
+		ci, cj := toPlace[i], toPlace[j]
+		if graph.Degrees[ci] != graph.Degrees[cj] {
+			return graph.Degrees[ci] > graph.Degrees[cj]
+		}
+		return graph.Courses[ci] < graph.Courses[cj]
+	})
+
+	// Kept courses stay where they are; the others may use their allowed slots that leave related
+	// courses a slot satisfying the relation.
+	available := make([]map[int]bool, len(graph.Courses))
+	for i, courseID := range graph.Courses {
+		available[i] = make(map[int]bool)
+		if slotIdx, ok := coloring[courseID]; ok {
+			available[i][slotIdx] = true
+			continue
+		}
+		for slotIdx, slot := range slots {
+			if allowed, ok := allowedSlots[courseID]; !ok || len(allowed) == 0 || allowed[slot.ID] {
+				available[i][slotIdx] = true
+			}
+		}
+	}
+	relations := constraints.relations()
+	if courseID, empty := narrowSlots(relations, graph, slots, available); empty {
+		return nil, nil, fmt.Errorf("cannot repair schedule: no slot for course %s satisfies its course relations", courseID)
+	}
+
+	minGap := time.Duration(minGapMinutes) * time.Minute
+	for _, ci := range toPlace {
+		courseID := graph.Courses[ci]
+		origin := -1
+		if a, ok := previous[courseID]; ok {
+			if idx, ok := slotIndex[a.SlotID]; ok {
+				origin = idx
+			}
+		}
+
+		bestSlot := -1
+		bestCost := 0.0
+		for slotIdx, slot := range slots {
+			if !available[ci][slotIdx] || !relIndex.allows(courseID, slot, placed) {
+				continue
+			}
+			feasible := true
+			cost := 0.0
+			for nj, weight := range graph.AdjMatrix[ci] {
+				if weight == 0 {
+					continue
+				}
+				other, ok := coloring[graph.Courses[nj]]
+				if !ok {
+					continue
+				}
+				if other == slotIdx {
+					feasible = false
+					break
+				}
+				if slots[other].DayIndex == slot.DayIndex {
+					cost += float64(weight) * penaltyConfig.StudentProximityWeight
+				}
+				gap := slots[other].Start.Sub(slot.Start)
+				if gap < 0 {
+					gap = -gap
+				}
+				if minGapMinutes > 0 && gap < minGap {
+					cost += float64(weight) * penaltyConfig.MinGapViolationWeight
+				}
+			}
+			if !feasible {
+				continue
+			}
+			if bestSlot == -1 || cost < bestCost ||
+				(cost == bestCost && origin >= 0 && abs(slotIdx-origin) < abs(bestSlot-origin)) {
+				bestSlot = slotIdx
+				bestCost = cost
+			}
+		}
+		if bestSlot == -1 {
+			return nil, nil, fmt.Errorf("cannot repair schedule: no clash-free slot for course %s", courseID)
+		}
+		coloring[courseID] = bestSlot
+		if len(relations) > 0 {
+			available[ci] = map[int]bool{bestSlot: true}
+			if other, empty := narrowSlots(relations, graph, slots, available); empty {
+				return nil, nil, fmt.Errorf("cannot repair schedule: no slot left for course %s after placing %s", other, courseID)
+			}
+		}
+	}
+
+	// Rebuild assignments, keeping halls for courses that did not move if the halls still fit, have the
+	// required features and are available.
+	hallMap := make(map[HallID]*Hall, len(halls))
+	for _, h := range halls {
+		hallMap[h.ID] = h
+	}
+	usedHalls := make(map[SlotID]map[HallID]bool)
+	assignmentsBySlot := make(map[int][]*Assignment)
+	allAssignments := make([]*Assignment, 0, len(coloring))
+	for _, courseID := range sortedCourseIDs(coloring) {
+		slotIdx := coloring[courseID]
+		slot := slots[slotIdx]
+		assignment := &Assignment{
+			CourseID:          courseID,
+			SlotID:            slot.ID,
+			SlotDateTime:      slot.Start.Format(time.RFC3339),
+			EnrolledCount:     len(courses[courseID].Enrollments),
+			AccommodatedCount: constraints.AccommodatedCount(courses[courseID]),
+			RequiredFeatures:  constraints.RequiredFeatures(courseID),
+		}
+		allAssignments = append(allAssignments, assignment)
+
+		if prev, ok := previous[courseID]; ok && prev.SlotID == slot.ID && prev.Halls != "" {
+			capacity, suitable := 0, true
+			for _, h := range strings.Split(prev.Halls, ";") {
+				hall, ok := hallMap[HallID(h)]
+				if !ok || !hall.HasFeatures(assignment.RequiredFeatures) || !constraints.HallAvailable(hall.ID, slot) {
+					suitable = false
+					break
+				}
+				capacity += hall.Capacity
+			}
+			if suitable && capacity >= assignment.EnrolledCount {
+				assignment.Halls = prev.Halls
+				if usedHalls[slot.ID] == nil {
+					usedHalls[slot.ID] = make(map[HallID]bool)
+				}
+				for _, h := range strings.Split(prev.Halls, ";") {
+					usedHalls[slot.ID][HallID(h)] = true
+				}
+				continue
+			}
+		}
+		assignmentsBySlot[slotIdx] = append(assignmentsBySlot[slotIdx], assignment)
+	}
+
+	var capacityWarnings []string
+	for _, slotIdx := range sortedSlotIndexes(assignmentsBySlot) {
+		assignmentsInSlot := assignmentsBySlot[slotIdx]
+		slotID := slots[slotIdx].ID
+		unavailable := constraints.UnavailableHalls(halls, slots[slotIdx])
+		_, warnings, err := AllocateHallsWithAvailability(assignmentsInSlot, halls, usedHalls, slotID, unavailable)
+		if err != nil {
+			return nil, nil, fmt.Errorf("hall allocation failed for slot %s: %w", slotID, err)
+		}
+		capacityWarnings = append(capacityWarnings, warnings...)
+	}
+
+	sort.Slice(allAssignments, func(i, j int) bool {
+		if allAssignments[i].SlotDateTime != allAssignments[j].SlotDateTime {
+			return allAssignments[i].SlotDateTime < allAssignments[j].SlotDateTime
+		}
+		return allAssignments[i].CourseID < allAssignments[j].CourseID
+	})
+
+	breakdown := calculatePenaltyBreakdown(coloring, courses, slots, minGapMinutes, penaltyConfig)
+	travelWarnings, travelIssues := campusTravel(allAssignments, courses, halls, slots, constraints.travel())
+	breakdown.CampusTravel = newPenaltyTerm(travelIssues, penaltyConfig.CampusTravelWeight)
+
+	result := &ScheduleResult{
+		Assignments: allAssignments,
+		Penalty:     breakdown.Total(),
+		Breakdown:   breakdown,
+		Report:      &ValidationReport{CapacityWarnings: capacityWarnings, TravelWarnings: travelWarnings},
+		Window:      ComputeExamWindow(allAssignments, slots),
+	}
+
+	return result, DiffSchedules(published, allAssignments, courses).Changes, nil
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
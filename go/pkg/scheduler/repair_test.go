@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"testing"
+)
+
+func TestRepairSchedule_MovesOnlyClashingCourse(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-07", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	halls := []*Hall{{ID: "H1", Capacity: 10}, {ID: "H2", Capacity: 10}}
+	published := []*Assignment{
+		{CourseID: "c1", SlotID: slots[0].ID, Halls: "H1", EnrolledCount: 2},
+		{CourseID: "c2", SlotID: slots[0].ID, Halls: "H2", EnrolledCount: 1},
+		{CourseID: "c3", SlotID: slots[1].ID, Halls: "H1", EnrolledCount: 1},
+	}
+
+	// s3 has added c2 to their registrations and now clashes between c1 and c2.
+	courses, _, _ := ParseRegistrations(`student_id,course_id
+s1,c1
+s3,c1
+s2,c2
+s3,c2
+s4,c3
+`, nil)
+	graph := NewConflictGraph(courses)
+
+	result, changes, err := RepairSchedule(published, courses, halls, slots, make(map[CourseID]map[SlotID]bool), graph, 0, PenaltyConfig{StudentProximityWeight: 1.0})
+	if err != nil {
+		t.Fatalf("RepairSchedule failed: %v", err)
+	}
+
+	if len(result.Assignments) != 3 {
+		t.Fatalf("expected 3 assignments, got %d", len(result.Assignments))
+	}
+	if len(changes) != 1 {
+		t.Fatalf("expected exactly 1 change, got %d: %+v", len(changes), changes)
+	}
+
+	assignmentMap := make(map[CourseID]*Assignment)
+	for _, a := range result.Assignments {
+		assignmentMap[a.CourseID] = a
+	}
+	if assignmentMap["c1"].SlotID == assignmentMap["c2"].SlotID {
+		t.Error("c1 and c2 still clash after repair")
+	}
+	if assignmentMap["c3"].SlotID != slots[1].ID || assignmentMap["c3"].Halls != "H1" {
+		t.Errorf("c3 should not have moved, got %+v", assignmentMap["c3"])
+	}
+	if len(changes[0].AffectedStudents) != 2 {
+		t.Errorf("expected 2 affected students, got %v", changes[0].AffectedStudents)
+	}
+}
+
+func TestRepairSchedule_NewAndDroppedCourses(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-06", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	halls := []*Hall{{ID: "H1", Capacity: 10}}
+	published := []*Assignment{
+		{CourseID: "c1", SlotID: slots[0].ID, Halls: "H1", EnrolledCount: 1},
+		{CourseID: "old", SlotID: slots[1].ID, Halls: "H1", EnrolledCount: 1},
+	}
+	courses, _, _ := ParseRegistrations(`student_id,course_id
+s1,c1
+s1,new
+`, nil)
+	graph := NewConflictGraph(courses)
+
+	result, changes, err := RepairSchedule(published, courses, halls, slots, make(map[CourseID]map[SlotID]bool), graph, 0, PenaltyConfig{})
+	if err != nil {
+		t.Fatalf("RepairSchedule failed: %v", err)
+	}
+	if len(result.Assignments) != 2 {
+		t.Fatalf("expected 2 assignments, got %d", len(result.Assignments))
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	if changes[0].CourseID != "new" || changes[0].FromSlot != "" || changes[0].ToSlot != slots[1].ID {
+		t.Errorf("unexpected change for new course: %+v", changes[0])
+	}
+	if changes[1].CourseID != "old" || changes[1].ToSlot != "" {
+		t.Errorf("unexpected change for dropped course: %+v", changes[1])
+	}
+}
+
+func TestRepairSchedule_MovesAliasedCoursesTogether(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-06", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	halls := []*Hall{{ID: "H1", Capacity: 10}, {ID: "H2", Capacity: 10}}
+	published := []*Assignment{
+		{CourseID: "CS301", SlotID: slots[0].ID, Halls: "H1", EnrolledCount: 1},
+		{CourseID: "ECE301", SlotID: slots[0].ID, Halls: "H1", EnrolledCount: 1},
+		{CourseID: "MA101", SlotID: slots[0].ID, Halls: "H2", EnrolledCount: 3},
+	}
+
+	// s2 has added MA101, which now clashes with the exam shared by CS301 and ECE301.
+	courses, _, _ := ParseRegistrations(`student_id,course_id
+s1,CS301
+s2,ECE301
+s2,MA101
+s3,MA101
+s4,MA101
+`, nil)
+	aliases := CourseAliases{"ECE301": "CS301"}
+	exams, examAllowed, examConstraints, err := MergeAliases(aliases, courses, nil, &Constraints{Aliases: aliases})
+	if err != nil {
+		t.Fatalf("MergeAliases failed: %v", err)
+	}
+
+	result, changes, err := RepairScheduleWithConstraints(published, exams, halls, slots, examAllowed, NewConflictGraph(exams), 0, PenaltyConfig{}, examConstraints)
+	if err != nil {
+		t.Fatalf("RepairSchedule failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].CourseID != "CS301" {
+		t.Fatalf("expected only the shared exam to move, got %+v", changes)
+	}
+
+	rows := make(map[CourseID]*Assignment)
+	for _, a := range ExpandAliases(result.Assignments, courses, aliases) {
+		rows[a.CourseID] = a
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows after expansion, got %d", len(rows))
+	}
+	if rows["CS301"].SlotID != rows["ECE301"].SlotID || rows["CS301"].SlotID == rows["MA101"].SlotID {
+		t.Errorf("expected CS301 and ECE301 to move together away from MA101, got %+v, %+v and %+v", rows["CS301"], rows["ECE301"], rows["MA101"])
+	}
+}
@@ -0,0 +1,268 @@
+package scheduler
+
+import (
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// WriteHTMLReport renders the schedule as a self-contained HTML document for printing or
+// posting: a grid of exams by day and slot, the occupancy of each hall, a listing per department
+// and the validation summary. Departments are the leading letters of the course codes, so CS101
+// belongs to CS. The report may be nil to leave out the validation summary. The page has print
+// styles, so a PDF can be made with the browser's print dialog.
+func WriteHTMLReport(w io.Writer, title string, assignments []*Assignment, slots []*Slot, halls []*Hall, report *ValidationReport) error {
+	return reportTemplate.Execute(w, buildReport(title, assignments, slots, halls, report))
+}
+
+type reportData struct {
+	Title       string
+	Exams       int
+	FirstDay    string
+	LastDay     string
+	SlotNumbers []int // Headings of the grid columns
+	Days        []reportDay
+	HallSlots   []reportSlot
+	Halls       []reportHall
+	Departments []reportDepartment
+	Unscheduled []reportExam // Exams in a slot that is not in the timetable
+	Report      *ValidationReport
+}
+
+type reportDay struct {
+	Date  string
+	Cells []*reportCell // By index in day; nil where the day has no such slot
+}
+
+type reportCell struct {
+	Time  string
+	Exams []reportExam
+}
+
+type reportSlot struct {
+	Date string
+	Time string
+}
+
+type reportHall struct {
+	ID       HallID
+	Group    string
+	Capacity int
+	Used     int
+	Cells    []string // Course in each of HallSlots
+}
+
+type reportDepartment struct {
+	Name  string
+	Exams []reportExam
+}
+
+type reportExam struct {
+	CourseID CourseID
+	Date     string
+	Time     string
+	Halls    string
+	Enrolled int
+}
+
+// buildReport arranges the schedule for the report template.
+func buildReport(title string, assignments []*Assignment, slots []*Slot, halls []*Hall, report *ValidationReport) *reportData {
+	data := &reportData{Title: title, Exams: len(assignments), Report: report}
+	if data.Title == "" {
+		data.Title = "Exam timetable"
+	}
+
+	slotByID := make(map[SlotID]*Slot, len(slots))
+	for _, s := range slots {
+		slotByID[s.ID] = s
+	}
+	sorted := make([]*Assignment, len(assignments))
+	copy(sorted, assignments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].SlotDateTime != sorted[j].SlotDateTime {
+			return sorted[i].SlotDateTime < sorted[j].SlotDateTime
+		}
+		return sorted[i].CourseID < sorted[j].CourseID
+	})
+
+	// Day-by-slot grid, covering every day from the first to the last exam
+	bySlot := make(map[SlotID][]reportExam)
+	departments := make(map[string][]reportExam)
+	firstDay, lastDay := -1, -1
+	for _, a := range sorted {
+		exam := reportExam{CourseID: a.CourseID, Date: a.SlotDateTime, Halls: strings.ReplaceAll(a.Halls, ";", ", "), Enrolled: a.EnrolledCount}
+		slot, ok := slotByID[a.SlotID]
+		if !ok {
+			data.Unscheduled = append(data.Unscheduled, exam)
+			continue
+		}
+		exam.Date, exam.Time = reportDate(slot), reportTime(slot)
+		bySlot[slot.ID] = append(bySlot[slot.ID], exam)
+		departments[departmentOf(a.CourseID)] = append(departments[departmentOf(a.CourseID)], exam)
+		if firstDay == -1 || slot.DayIndex < firstDay {
+			firstDay = slot.DayIndex
+		}
+		lastDay = max(lastDay, slot.DayIndex)
+	}
+	for _, exam := range data.Unscheduled {
+		departments[departmentOf(exam.CourseID)] = append(departments[departmentOf(exam.CourseID)], exam)
+	}
+
+	var usedSlots []*Slot
+	for _, s := range slots {
+		if s.DayIndex < firstDay || s.DayIndex > lastDay {
+			continue
+		}
+		if len(data.Days) == 0 || data.Days[len(data.Days)-1].Date != reportDate(s) {
+			data.Days = append(data.Days, reportDay{Date: reportDate(s)})
+		}
+		day := &data.Days[len(data.Days)-1]
+		for len(day.Cells) <= s.IndexInDay {
+			day.Cells = append(day.Cells, nil)
+		}
+		day.Cells[s.IndexInDay] = &reportCell{Time: reportTime(s), Exams: bySlot[s.ID]}
+		for len(data.SlotNumbers) < len(day.Cells) {
+			data.SlotNumbers = append(data.SlotNumbers, len(data.SlotNumbers)+1)
+		}
+		if len(bySlot[s.ID]) > 0 {
+			usedSlots = append(usedSlots, s)
+		}
+	}
+	for i := range data.Days {
+		for len(data.Days[i].Cells) < len(data.SlotNumbers) {
+			data.Days[i].Cells = append(data.Days[i].Cells, nil)
+		}
+	}
+	if len(data.Days) > 0 {
+		data.FirstDay, data.LastDay = data.Days[0].Date, data.Days[len(data.Days)-1].Date
+	}
+
+	// Hall occupancy over the slots that hold exams
+	courseInHall := make(map[SlotID]map[HallID][]string)
+	for _, a := range sorted {
+		if courseInHall[a.SlotID] == nil {
+			courseInHall[a.SlotID] = make(map[HallID][]string)
+		}
+		for _, id := range splitHalls(a.Halls) {
+			courseInHall[a.SlotID][id] = append(courseInHall[a.SlotID][id], string(a.CourseID))
+		}
+	}
+	for _, s := range usedSlots {
+		data.HallSlots = append(data.HallSlots, reportSlot{Date: reportDate(s), Time: reportTime(s)})
+	}
+	for _, h := range halls {
+		row := reportHall{ID: h.ID, Group: h.Group, Capacity: h.Capacity}
+		for _, s := range usedSlots {
+			courses := courseInHall[s.ID][h.ID]
+			if len(courses) > 0 {
+				row.Used++
+			}
+			row.Cells = append(row.Cells, strings.Join(courses, ", "))
+		}
+		data.Halls = append(data.Halls, row)
+	}
+
+	names := make([]string, 0, len(departments))
+	for name := range departments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data.Departments = append(data.Departments, reportDepartment{Name: name, Exams: departments[name]})
+	}
+	return data
+}
+
+// departmentOf returns the department of a course: the leading letters of its code, upper-cased.
+func departmentOf(id CourseID) string {
+	code := strings.TrimSpace(string(id))
+	end := strings.IndexFunc(code, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end == -1 {
+		end = len(code)
+	}
+	if end == 0 {
+		return "Other"
+	}
+	return strings.ToUpper(code[:end])
+}
+
+func reportDate(s *Slot) string {
+	return s.Start.Format("Mon 2 Jan 2006")
+}
+
+func reportTime(s *Slot) string {
+	return s.Start.Format("15:04") + "–" + s.End.Format("15:04")
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; font-size: 11pt; margin: 2em; color: #222; }
+h1 { margin-bottom: 0.2em; }
+h2 { margin-top: 2em; border-bottom: 1px solid #999; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1em; }
+th, td { border: 1px solid #bbb; padding: 0.3em 0.5em; text-align: left; vertical-align: top; }
+th { background: #eee; }
+td.empty { background: #f6f6f6; }
+.time { color: #666; font-size: 90%; }
+.exam { margin-bottom: 0.3em; }
+.valid { color: #1a7f37; }
+.invalid { color: #c62828; }
+.summary { color: #555; }
+@media print {
+  body { margin: 0; font-size: 9pt; }
+  section { page-break-before: always; }
+  section:first-of-type { page-break-before: auto; }
+  tr { page-break-inside: avoid; }
+}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="summary">{{.Exams}} exams{{if .FirstDay}}, {{.FirstDay}} to {{.LastDay}}{{end}}</p>
+
+<section>
+<h2>Timetable</h2>
+{{if .Days}}<table>
+<tr><th>Day</th>{{range .SlotNumbers}}<th>Slot {{.}}</th>{{end}}</tr>
+{{range .Days}}<tr><th>{{.Date}}</th>{{range .Cells}}{{if .}}<td><div class="time">{{.Time}}</div>{{range .Exams}}<div class="exam"><strong>{{.CourseID}}</strong> {{.Halls}} ({{.Enrolled}})</div>{{end}}</td>{{else}}<td class="empty"></td>{{end}}{{end}}</tr>
+{{end}}</table>{{else}}<p>No exams are scheduled.</p>{{end}}
+{{if .Unscheduled}}<p>Exams in slots outside the timetable:</p>
+<ul>{{range .Unscheduled}}<li><strong>{{.CourseID}}</strong> {{.Date}} {{.Halls}}</li>{{end}}</ul>{{end}}
+</section>
+
+{{if .Halls}}<section>
+<h2>Hall occupancy</h2>
+<table>
+<tr><th>Hall</th><th>Capacity</th><th>Used</th>{{range .HallSlots}}<th>{{.Date}}<div class="time">{{.Time}}</div></th>{{end}}</tr>
+{{$slots := len .HallSlots}}{{range .Halls}}<tr><th>{{.ID}}{{if .Group}}<div class="time">{{.Group}}</div>{{end}}</th><td>{{.Capacity}}</td><td>{{.Used}} of {{$slots}}</td>{{range .Cells}}{{if .}}<td>{{.}}</td>{{else}}<td class="empty"></td>{{end}}{{end}}</tr>
+{{end}}</table>
+</section>
+{{end}}
+<section>
+<h2>Departments</h2>
+{{range .Departments}}<h3>{{.Name}}</h3>
+<table>
+<tr><th>Course</th><th>Date</th><th>Time</th><th>Halls</th><th>Students</th></tr>
+{{range .Exams}}<tr><td>{{.CourseID}}</td><td>{{.Date}}</td><td>{{.Time}}</td><td>{{.Halls}}</td><td>{{.Enrolled}}</td></tr>
+{{end}}</table>
+{{end}}</section>
+{{with .Report}}
+<section>
+<h2>Validation</h2>
+{{if .Valid}}<p class="valid">The schedule is valid.</p>{{else}}<p class="invalid">The schedule is not valid: {{.Conflicts}} student conflicts.</p>{{end}}
+{{if .Unassigned}}<h3>Unassigned courses</h3><ul>{{range .Unassigned}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .StudentClashes}}<h3>Student clashes</h3><ul>{{range .StudentClashes}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .ConstraintViolations}}<h3>Constraint violations</h3><ul>{{range .ConstraintViolations}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .CapacityWarnings}}<h3>Capacity warnings</h3><ul>{{range .CapacityWarnings}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .TravelWarnings}}<h3>Travel warnings</h3><ul>{{range .TravelWarnings}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .Errors}}<h3>Errors</h3><ul>{{range .Errors}}<li>{{.}}</li>{{end}}</ul>{{end}}
+</section>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-08", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	halls := []*Hall{{ID: "H1", Capacity: 100, Group: "North"}, {ID: "H2", Capacity: 50}}
+	assignments := []*Assignment{
+		{CourseID: "MA101", SlotID: slots[2].ID, SlotDateTime: "2025-01-07T09:00:00Z", Halls: "H1", EnrolledCount: 80},
+		{CourseID: "CS101", SlotID: slots[0].ID, SlotDateTime: "2025-01-06T09:00:00Z", Halls: "H1;H2", EnrolledCount: 120},
+		{CourseID: "cs<b>2</b>", SlotID: slots[1].ID, SlotDateTime: "2025-01-06T14:00:00Z", Halls: "H2", EnrolledCount: 10},
+		{CourseID: "101", SlotID: "elsewhere", SlotDateTime: "2025-02-01T09:00:00Z", EnrolledCount: 5},
+	}
+	report := &ValidationReport{Valid: false, Conflicts: 1, StudentClashes: []string{"s1 has CS101 and MA101"}}
+
+	var sb strings.Builder
+	if err := WriteHTMLReport(&sb, "Winter exams", assignments, slots, halls, report); err != nil {
+		t.Fatalf("WriteHTMLReport failed: %v", err)
+	}
+	html := sb.String()
+
+	for _, want := range []string{
+		"<title>Winter exams</title>",
+		"4 exams, Mon 6 Jan 2025 to Tue 7 Jan 2025",
+		"<th>Slot 2</th>",
+		"<strong>CS101</strong> H1, H2 (120)",
+		"cs&lt;b&gt;2&lt;/b&gt;",                                               // Course IDs are escaped
+		"<td>2 of 3</td><td>CS101</td><td class=\"empty\"></td><td>MA101</td>", // H1 over the used slots
+		"<h3>CS</h3>", "<h3>MA</h3>", "<h3>Other</h3>",
+		"Exams in slots outside the timetable",
+		"1 student conflicts", "<li>s1 has CS101 and MA101</li>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected the report to contain %q", want)
+		}
+	}
+	if strings.Contains(html, "Wed 8 Jan") {
+		t.Error("expected days after the last exam to be left out of the grid")
+	}
+	if strings.Contains(html, "cs<b>") {
+		t.Error("expected course IDs to be escaped")
+	}
+}
+
+func TestDepartmentOf(t *testing.T) {
+	tests := map[CourseID]string{"CS101": "CS", "math-201": "MATH", " PHYS ": "PHYS", "101": "Other", "": "Other"}
+	for id, want := range tests {
+		if got := departmentOf(id); got != want {
+			t.Errorf("departmentOf(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ScenarioVersion is the version of the scenario format written by SaveScenario. LoadScenario
+// reads this and every earlier version.
+const ScenarioVersion = 1
+
+// Scenario bundles everything needed to archive a scheduling session and re-run it exactly:
+// the input files as CSV text, the slot configuration, constraints, penalty weights and seed.
+type Scenario struct {
+	Version int    `json:"version"`
+	Name    string `json:"name,omitempty"`
+
+	RegistrationsCSV string         `json:"registrationsCSV"`
+	HallsCSV         string         `json:"hallsCSV"`
+	AllowedSlotsCSV  string         `json:"allowedSlotsCSV,omitempty"`
+	ColumnMapping    *ColumnMapping `json:"columnMapping,omitempty"`
+	StrictInput      bool           `json:"strictInput,omitempty"`
+
+	Slots       ScenarioSlots       `json:"slots"`
+	Constraints ScenarioConstraints `json:"constraints"`
+	Penalty     ScenarioPenalty     `json:"penalty"`
+
+	Seed      int64  `json:"seed"` // Seed of the run; SaveScenario rejects 0, which picks a new seed on every run
+	Tries     int    `json:"tries"`
+	MinGap    int    `json:"minGap"`              // Minimum gap between a student's exams in minutes
+	Objective string `json:"objective,omitempty"` // "penalty" (default) or "minDays"
+}
+
+// ScenarioSlots configures the exam slots, as for GenerateSlots. SlotsCSV, if set, lists the
+// slots explicitly instead, as for ParseSlots.
+type ScenarioSlots struct {
+	StartDate    string   `json:"startDate,omitempty"`
+	EndDate      string   `json:"endDate,omitempty"`
+	SlotsPerDay  int      `json:"slotsPerDay,omitempty"`
+	SlotTimes    []string `json:"slotTimes,omitempty"`
+	SlotDuration int      `json:"slotDuration,omitempty"` // Minutes
+	Holidays     []string `json:"holidays,omitempty"`
+	Timezone     string   `json:"timezone,omitempty"` // IANA name; UTC if empty
+	SlotsCSV     string   `json:"slotsCSV,omitempty"`
+}
+
+// ScenarioConstraints holds the optional constraint inputs as CSV text.
+type ScenarioConstraints struct {
+	StudentConstraintsCSV string `json:"studentConstraintsCSV,omitempty"`
+	CourseRelationsCSV    string `json:"courseRelationsCSV,omitempty"`
+	ForbiddenSlotsCSV     string `json:"forbiddenSlotsCSV,omitempty"`
+	CourseRequirementsCSV string `json:"courseRequirementsCSV,omitempty"`
+	HallAvailabilityCSV   string `json:"hallAvailabilityCSV,omitempty"`
+	TravelTimesCSV        string `json:"travelTimesCSV,omitempty"`
+	CourseAliasesCSV      string `json:"courseAliasesCSV,omitempty"`
+	CourseSittingsCSV     string `json:"courseSittingsCSV,omitempty"`
+}
+
+// ScenarioPenalty holds the penalty weights as they were given. A nil weight takes its default
+// and 0 turns the penalty off, as for the run itself.
+type ScenarioPenalty struct {
+	StudentProximityWeight *float64 `json:"studentProximityWeight,omitempty"`
+	MinGapViolationWeight  *float64 `json:"minGapViolationWeight,omitempty"`
+	CampusTravelWeight     *float64 `json:"campusTravelWeight,omitempty"`
+}
+
+// LoadScenario reads a scenario saved by SaveScenario. A scenario without a version, from a
+// newer version of the format, or without registrations or halls is an error.
+func LoadScenario(r io.Reader) (*Scenario, error) {
+	var s Scenario
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid scenario: %w", err)
+	}
+	if s.Version == 0 {
+		return nil, fmt.Errorf("invalid scenario: missing version")
+	}
+	if s.Version > ScenarioVersion {
+		return nil, fmt.Errorf("scenario version %d is newer than the supported version %d", s.Version, ScenarioVersion)
+	}
+	if s.RegistrationsCSV == "" || s.HallsCSV == "" {
+		return nil, fmt.Errorf("invalid scenario: registrations and halls are required")
+	}
+	return &s, nil
+}
+
+// SaveScenario writes the scenario as indented JSON, stamped with the current ScenarioVersion.
+// A scenario without a seed could not be repeated and is an error.
+func SaveScenario(w io.Writer, s *Scenario) error {
+	if s.Seed == 0 {
+		return fmt.Errorf("scenario has no seed; use the seed reported by the run")
+	}
+	out := *s
+	out.Version = ScenarioVersion
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&out)
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSaveLoadScenario(t *testing.T) {
+	proximity, travel := 0.0, 5.0
+	s := &Scenario{
+		Name:             "Spring 2025",
+		RegistrationsCSV: validRegCSV,
+		HallsCSV:         validHallsCSV,
+		AllowedSlotsCSV:  validAllowedSlotsCSV,
+		ColumnMapping:    &ColumnMapping{CourseDelimiter: ";"},
+		Slots: ScenarioSlots{
+			StartDate:    "2025-01-06",
+			EndDate:      "2025-01-10",
+			SlotsPerDay:  2,
+			SlotTimes:    []string{"09:00", "14:00"},
+			SlotDuration: 180,
+			Timezone:     "Europe/Berlin",
+		},
+		Constraints: ScenarioConstraints{CourseAliasesCSV: "course_id,exam_id\nc1,c2\n"},
+		Penalty:     ScenarioPenalty{StudentProximityWeight: &proximity, CampusTravelWeight: &travel},
+		Seed:        42,
+		Tries:       100,
+		MinGap:      60,
+	}
+
+	var buf bytes.Buffer
+	if err := SaveScenario(&buf, s); err != nil {
+		t.Fatalf("SaveScenario failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"version": 1`) {
+		t.Errorf("expected the saved scenario to carry its version, got %s", buf.String())
+	}
+
+	loaded, err := LoadScenario(&buf)
+	if err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+	s.Version = ScenarioVersion
+	if !reflect.DeepEqual(loaded, s) {
+		t.Errorf("expected the scenario back, got %+v", loaded)
+	}
+	if loaded.Penalty.MinGapViolationWeight != nil || *loaded.Penalty.StudentProximityWeight != 0 {
+		t.Errorf("expected the weights as given, got %+v", loaded.Penalty)
+	}
+
+	s.Seed = 0
+	if err := SaveScenario(&buf, s); err == nil {
+		t.Error("expected an error for a scenario without a seed")
+	}
+}
+
+func TestLoadScenario_Invalid(t *testing.T) {
+	tests := []struct {
+		name, json, want string
+	}{
+		{"no version", `{"registrationsCSV":"a","hallsCSV":"b"}`, "missing version"},
+		{"newer version", `{"version":99,"registrationsCSV":"a","hallsCSV":"b"}`, "newer than the supported version"},
+		{"no halls", `{"version":1,"registrationsCSV":"a"}`, "registrations and halls are required"},
+		{"not JSON", `student_id,course_id`, "invalid scenario"},
+	}
+	for _, tt := range tests {
+		if _, err := LoadScenario(strings.NewReader(tt.json)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
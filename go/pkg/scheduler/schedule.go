@@ -11,8 +11,13 @@ import (
 type ScheduleResult struct {
 	Assignments []*Assignment
 	Penalty     float64
+	Breakdown   *PenaltyBreakdown // Components of Penalty
+	Seed        int64             // Seed of the run; the same seed and inputs give the same schedule
 	Unassigned  []CourseID
 	Report      *ValidationReport
+	Window      *ExamWindow
+
+	FailedAttempts int // Attempts that found no feasible colouring
 }
 
 // RunSchedulingAttempts runs the scheduling algorithm multiple times and returns the best result.
@@ -27,9 +32,33 @@ func RunSchedulingAttempts(
 	minGapMinutes int,
 	penaltyConfig PenaltyConfig,
 ) (*ScheduleResult, error) {
+	return RunSchedulingAttemptsWithConstraints(tries, seed, courses, halls, slots, allowedSlots, graph, minGapMinutes, penaltyConfig, nil)
+}
+
+// RunSchedulingAttemptsWithConstraints runs RunSchedulingAttempts with the given constraints,
+// which may be nil.
+func RunSchedulingAttemptsWithConstraints(
+	tries int,
+	seed int64,
+	courses map[CourseID]*Course,
+	halls []*Hall,
+	slots []*Slot,
+	allowedSlots map[CourseID]map[SlotID]bool,
+	graph *ConflictGraph,
+	minGapMinutes int,
+	penaltyConfig PenaltyConfig,
+	constraints *Constraints,
+) (*ScheduleResult, error) {
+
+	allowedSlots, err := RestrictAllowedSlots(allowedSlots, courses, slots, constraints)
+	if err != nil {
+		return nil, err
+	}
 
 	var bestResult *ScheduleResult
 	bestPenalty := -1.0
+	var lastErr error
+	failed := 0
 
 	if seed == 0 {
 		seed = time.Now().UnixNano()
@@ -39,12 +68,12 @@ func RunSchedulingAttempts(
 	for i := 0; i < tries; i++ {
 		attemptSeed := rng.Int63()
 
-		coloring, err := DSATUR(graph, slots, allowedSlots, attemptSeed)
+		coloring, err := DSATURWithRelations(graph, slots, allowedSlots, constraints.relations(), attemptSeed)
 		if err != nil {
-			// If one attempt is infeasible, it might be due to the random tie-breaking.
-			// We can continue trying, but if all fail, we should report it.
-			// For now, we'll just log it and continue.
-			fmt.Printf("Attempt %d failed: %v\n", i+1, err)
+			// One attempt may be infeasible because of the random tie-breaking; keep trying and
+			// report the reason if every attempt fails.
+			lastErr = err
+			failed++
 			continue
 		}
 
@@ -52,13 +81,16 @@ func RunSchedulingAttempts(
 		assignmentsBySlot := make(map[int][]*Assignment)
 		allAssignments := make([]*Assignment, 0, len(coloring))
 
-		for courseID, slotIdx := range coloring {
+		for _, courseID := range sortedCourseIDs(coloring) {
+			slotIdx := coloring[courseID]
 			slot := slots[slotIdx]
 			assignment := &Assignment{
-				CourseID:      courseID,
-				SlotID:        slot.ID,
-				SlotDateTime:  slot.Start.Format(time.RFC3339),
-				EnrolledCount: len(courses[courseID].Enrollments),
+				CourseID:          courseID,
+				SlotID:            slot.ID,
+				SlotDateTime:      slot.Start.Format(time.RFC3339),
+				EnrolledCount:     len(courses[courseID].Enrollments),
+				AccommodatedCount: constraints.AccommodatedCount(courses[courseID]),
+				RequiredFeatures:  constraints.RequiredFeatures(courseID),
 			}
 			assignmentsBySlot[slotIdx] = append(assignmentsBySlot[slotIdx], assignment)
 			allAssignments = append(allAssignments, assignment)
@@ -67,17 +99,22 @@ func RunSchedulingAttempts(
 		// Allocate halls for each slot
 		usedHalls := make(map[SlotID]map[HallID]bool)
 		var allCapacityWarnings []string
-		for slotIdx, assignmentsInSlot := range assignmentsBySlot {
+		for _, slotIdx := range sortedSlotIndexes(assignmentsBySlot) {
+			assignmentsInSlot := assignmentsBySlot[slotIdx]
 			slotID := slots[slotIdx].ID
-			_, warnings, err := AllocateHalls(assignmentsInSlot, halls, usedHalls, slotID)
+			unavailable := constraints.UnavailableHalls(halls, slots[slotIdx])
+			_, warnings, err := AllocateHallsWithAvailability(assignmentsInSlot, halls, usedHalls, slotID, unavailable)
 			if err != nil {
 				return nil, fmt.Errorf("hall allocation failed for slot %s: %w", slotID, err)
 			}
 			allCapacityWarnings = append(allCapacityWarnings, warnings...)
 		}
 
-		// Calculate penalty
-		penalty := CalculatePenalty(coloring, courses, slots, graph, minGapMinutes, penaltyConfig)
+		// Calculate penalty, including campus travel now that halls are known
+		breakdown := calculatePenaltyBreakdown(coloring, courses, slots, minGapMinutes, penaltyConfig)
+		travelWarnings, travelIssues := campusTravel(allAssignments, courses, halls, slots, constraints.travel())
+		breakdown.CampusTravel = newPenaltyTerm(travelIssues, penaltyConfig.CampusTravelWeight)
+		penalty := breakdown.Total()
 
 		if bestResult == nil || penalty < bestPenalty {
 			bestPenalty = penalty
@@ -93,17 +130,46 @@ func RunSchedulingAttempts(
 			bestResult = &ScheduleResult{
 				Assignments: allAssignments,
 				Penalty:     penalty,
+				Breakdown:   breakdown,
+				Seed:        seed,
 				Report: &ValidationReport{
 					CapacityWarnings: allCapacityWarnings,
+					TravelWarnings:   travelWarnings,
 					// Other report fields will be filled by the Verify function
 				},
+				Window: ComputeExamWindow(allAssignments, slots),
 			}
 		}
 	}
 
 	if bestResult == nil {
+		if lastErr != nil {
+			return nil, fmt.Errorf("failed to find a valid schedule after %d attempts: %w", tries, lastErr)
+		}
 		return nil, fmt.Errorf("failed to find a valid schedule after %d attempts", tries)
 	}
 
+	bestResult.FailedAttempts = failed
 	return bestResult, nil
 }
+
+// sortedCourseIDs returns the courses of a coloring in ID order, so that building the schedule
+// does not depend on map iteration order.
+func sortedCourseIDs(coloring map[CourseID]int) []CourseID {
+	ids := make([]CourseID, 0, len(coloring))
+	for courseID := range coloring {
+		ids = append(ids, courseID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// sortedSlotIndexes returns the slots that have assignments in slot order.
+func sortedSlotIndexes(assignmentsBySlot map[int][]*Assignment) []int {
+	indexes := make([]int, 0, len(assignmentsBySlot))
+	for slotIdx := range assignmentsBySlot {
+		indexes = append(indexes, slotIdx)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
@@ -0,0 +1,221 @@
+package scheduler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ScheduleExportVersion is the version of the JSON schedule format written by WriteScheduleJSON.
+// ReadScheduleJSON reads this and every earlier version.
+const ScheduleExportVersion = 1
+
+// ScheduleExport is a schedule with the metadata needed to use it without re-parsing the CSV:
+// the slots and halls it refers to, the seats of each exam, the penalty and how to reproduce it.
+type ScheduleExport struct {
+	Version    int               `json:"version"`
+	Seed       int64             `json:"seed"`             // Re-running the inputs with this seed gives the same schedule
+	Inputs     map[string]string `json:"inputs,omitempty"` // Checksum of each input file, by name
+	Penalty    float64           `json:"penalty"`
+	Breakdown  *PenaltyBreakdown `json:"penaltyBreakdown,omitempty"`
+	Window     *ExamWindow       `json:"window,omitempty"`
+	Slots      []ExportSlot      `json:"slots"`
+	Halls      []ExportHall      `json:"halls"`
+	Exams      []ExportExam      `json:"exams"`
+	Unassigned []CourseID        `json:"unassigned,omitempty"`
+}
+
+// ExportSlot is a slot of an exported schedule.
+type ExportSlot struct {
+	ID         SlotID    `json:"id"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	DayIndex   int       `json:"dayIndex"`
+	IndexInDay int       `json:"indexInDay"`
+}
+
+// ExportHall is a hall of an exported schedule.
+type ExportHall struct {
+	ID            HallID   `json:"id"`
+	Capacity      int      `json:"capacity"`
+	Group         string   `json:"group,omitempty"`
+	Accommodation bool     `json:"accommodation,omitempty"`
+	Features      []string `json:"features,omitempty"`
+}
+
+// ExportExam is one course of an exported schedule, with its slot and halls spelled out. Seats
+// is the capacity of its halls and Unseated the number of enrolled students beyond it.
+type ExportExam struct {
+	CourseID CourseID     `json:"courseId"`
+	SlotID   SlotID       `json:"slotId"`
+	Start    time.Time    `json:"start"`
+	End      time.Time    `json:"end"`
+	DayIndex int          `json:"dayIndex"`
+	Halls    []ExportHall `json:"halls"`
+	Enrolled int          `json:"enrolled"`
+	Seats    int          `json:"seats"`
+	Unseated int          `json:"unseated,omitempty"`
+	Notes    string       `json:"notes,omitempty"`
+}
+
+// Checksum returns the SHA-256 checksum of an input, as "sha256:" followed by the hex digest,
+// for the Inputs of a ScheduleExport.
+func Checksum(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// NewScheduleExport builds the export of a schedule result. Halls and slots are those the
+// schedule was made with; inputs holds the checksums of the input files and may be nil.
+// Assignments that refer to an unknown slot or hall are exported with what is known about them.
+func NewScheduleExport(result *ScheduleResult, halls []*Hall, slots []*Slot, inputs map[string]string) *ScheduleExport {
+	export := &ScheduleExport{
+		Version:    ScheduleExportVersion,
+		Seed:       result.Seed,
+		Inputs:     inputs,
+		Penalty:    result.Penalty,
+		Breakdown:  result.Breakdown,
+		Window:     result.Window,
+		Slots:      make([]ExportSlot, 0, len(slots)),
+		Halls:      make([]ExportHall, 0, len(halls)),
+		Exams:      make([]ExportExam, 0, len(result.Assignments)),
+		Unassigned: result.Unassigned,
+	}
+
+	slotByID := make(map[SlotID]*Slot, len(slots))
+	for _, s := range slots {
+		slotByID[s.ID] = s
+		export.Slots = append(export.Slots, ExportSlot{ID: s.ID, Start: s.Start, End: s.End, DayIndex: s.DayIndex, IndexInDay: s.IndexInDay})
+	}
+	hallByID := make(map[HallID]ExportHall, len(halls))
+	for _, h := range halls {
+		eh := ExportHall{ID: h.ID, Capacity: h.Capacity, Group: h.Group, Accommodation: h.Accommodation, Features: h.Features}
+		hallByID[h.ID] = eh
+		export.Halls = append(export.Halls, eh)
+	}
+
+	for _, a := range result.Assignments {
+		exam := ExportExam{
+			CourseID: a.CourseID,
+			SlotID:   a.SlotID,
+			Halls:    []ExportHall{},
+			Enrolled: a.EnrolledCount,
+			Notes:    a.Notes,
+		}
+		if slot, ok := slotByID[a.SlotID]; ok {
+			exam.Start, exam.End, exam.DayIndex = slot.Start, slot.End, slot.DayIndex
+		} else if start, err := time.Parse(time.RFC3339, a.SlotDateTime); err == nil {
+			exam.Start = start
+		}
+		for _, id := range splitHalls(a.Halls) {
+			hall, ok := hallByID[id]
+			if !ok {
+				hall = ExportHall{ID: id}
+			}
+			exam.Halls = append(exam.Halls, hall)
+			exam.Seats += hall.Capacity
+		}
+		exam.Unseated = max(0, exam.Enrolled-exam.Seats)
+		export.Exams = append(export.Exams, exam)
+	}
+	return export
+}
+
+// splitHalls splits the semicolon-separated halls of an assignment.
+func splitHalls(halls string) []HallID {
+	var ids []HallID
+	for _, h := range strings.Split(halls, ";") {
+		if h = strings.TrimSpace(h); h != "" {
+			ids = append(ids, HallID(h))
+		}
+	}
+	return ids
+}
+
+// WriteScheduleJSON writes the export as indented JSON, stamped with the current
+// ScheduleExportVersion.
+func WriteScheduleJSON(w io.Writer, export *ScheduleExport) error {
+	out := *export
+	out.Version = ScheduleExportVersion
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&out)
+}
+
+// ReadScheduleJSON reads a schedule written by WriteScheduleJSON. An export without a version,
+// from a newer version of the format, or with an exam in a slot it does not list is an error.
+func ReadScheduleJSON(r io.Reader) (*ScheduleExport, error) {
+	var export ScheduleExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("invalid schedule JSON: %w", err)
+	}
+	if export.Version == 0 {
+		return nil, fmt.Errorf("invalid schedule JSON: missing version")
+	}
+	if export.Version > ScheduleExportVersion {
+		return nil, fmt.Errorf("schedule JSON version %d is newer than the supported version %d", export.Version, ScheduleExportVersion)
+	}
+
+	slotIDs := make(map[SlotID]bool, len(export.Slots))
+	for _, s := range export.Slots {
+		slotIDs[s.ID] = true
+	}
+	for _, exam := range export.Exams {
+		if !slotIDs[exam.SlotID] {
+			return nil, fmt.Errorf("invalid schedule JSON: course %s is in slot %q, which is not listed", exam.CourseID, exam.SlotID)
+		}
+	}
+	return &export, nil
+}
+
+// Schedule converts the export back into assignments, slots and halls, as used by the rest of
+// the package.
+func (e *ScheduleExport) Schedule() ([]*Assignment, []*Slot, []*Hall) {
+	slots := make([]*Slot, 0, len(e.Slots))
+	for _, s := range e.Slots {
+		slots = append(slots, &Slot{ID: s.ID, Start: s.Start, End: s.End, DayIndex: s.DayIndex, IndexInDay: s.IndexInDay})
+	}
+	halls := make([]*Hall, 0, len(e.Halls))
+	for _, h := range e.Halls {
+		halls = append(halls, &Hall{ID: h.ID, Capacity: h.Capacity, Group: h.Group, Accommodation: h.Accommodation, Features: h.Features})
+	}
+
+	assignments := make([]*Assignment, 0, len(e.Exams))
+	for _, exam := range e.Exams {
+		ids := make([]string, len(exam.Halls))
+		for i, h := range exam.Halls {
+			ids[i] = string(h.ID)
+		}
+		assignments = append(assignments, &Assignment{
+			CourseID:      exam.CourseID,
+			SlotID:        exam.SlotID,
+			SlotDateTime:  exam.Start.Format(time.RFC3339),
+			Halls:         strings.Join(ids, ";"),
+			EnrolledCount: exam.Enrolled,
+			Notes:         exam.Notes,
+		})
+	}
+	return assignments, slots, halls
+}
+
+// VerifyScheduleExport runs the checks of VerifyScheduleWithConstraints on an exported schedule,
+// against the halls and slots it lists. Constraints may be nil.
+func VerifyScheduleExport(registrations []Registration, export *ScheduleExport, constraints *Constraints) *ValidationReport {
+	return VerifyScheduleExportWithSettings(registrations, export, nil, 0, constraints)
+}
+
+// VerifyScheduleExportWithSettings runs the checks of VerifyScheduleWithSettings on an exported
+// schedule, against the halls and slots it lists. allowedSlots and constraints may be nil.
+func VerifyScheduleExportWithSettings(registrations []Registration, export *ScheduleExport, allowedSlots map[CourseID]map[SlotID]bool, minGapMinutes int, constraints *Constraints) *ValidationReport {
+	report := &ValidationReport{Valid: true}
+	assignments, slots, halls := export.Schedule()
+	verifyWithSettings(report, registrations, assignments, halls, slots, allowedSlots, minGapMinutes, constraints)
+	return report
+}
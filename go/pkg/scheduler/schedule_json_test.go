@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScheduleJSON_RoundTrip(t *testing.T) {
+	regCSV := `student_id,course_id
+s1,c1
+s1,c2
+s2,c1
+s3,c3
+s3,c2
+`
+	hallsCSV := `hall,capacity,group
+H1,1,North
+H2,5,South
+`
+	courses, registrations, _ := ParseRegistrations(regCSV, nil)
+	halls, _ := ParseHalls(hallsCSV, nil)
+	slots, _ := GenerateSlots("2025-01-20", "2025-01-20", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+
+	result, err := RunSchedulingAttempts(5, 7, courses, halls, slots, nil, NewConflictGraph(courses), 0, PenaltyConfig{StudentProximityWeight: 2})
+	if err != nil {
+		t.Fatalf("RunSchedulingAttempts failed: %v", err)
+	}
+	if result.Seed != 7 {
+		t.Errorf("expected the seed to be recorded, got %d", result.Seed)
+	}
+	if b := result.Breakdown; b.StudentProximity.Count != 2 || b.StudentProximity.Penalty != 4 || b.Total() != result.Penalty {
+		t.Errorf("unexpected penalty breakdown %+v for penalty %v", b, result.Penalty)
+	}
+
+	checksum, err := Checksum(strings.NewReader(regCSV))
+	if err != nil || !strings.HasPrefix(checksum, "sha256:") || len(checksum) != 7+64 {
+		t.Fatalf("unexpected checksum %q: %v", checksum, err)
+	}
+	export := NewScheduleExport(result, halls, slots, map[string]string{"registrations": checksum})
+
+	var buf bytes.Buffer
+	if err := WriteScheduleJSON(&buf, export); err != nil {
+		t.Fatalf("WriteScheduleJSON failed: %v", err)
+	}
+	imported, err := ReadScheduleJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadScheduleJSON failed: %v", err)
+	}
+	if imported.Seed != 7 || imported.Inputs["registrations"] != checksum || len(imported.Slots) != 2 || len(imported.Halls) != 2 {
+		t.Errorf("metadata lost in the round trip: %+v", imported)
+	}
+
+	for _, exam := range imported.Exams {
+		if exam.Start.IsZero() || exam.End.Sub(exam.Start).Minutes() != 180 || exam.DayIndex != 0 {
+			t.Errorf("expected the slot of %s to be spelled out, got %+v", exam.CourseID, exam)
+		}
+		if exam.CourseID == "c1" && exam.Enrolled == 2 && exam.Seats < 2 {
+			t.Errorf("expected c1 to have seats for its students, got %+v", exam)
+		}
+		for _, h := range exam.Halls {
+			if (h.ID == "H1" && h.Group != "North") || (h.ID == "H2" && h.Capacity != 5) {
+				t.Errorf("expected hall details, got %+v", h)
+			}
+		}
+	}
+
+	report := VerifyScheduleExport(registrations, imported, nil)
+	if !report.Valid || report.Conflicts != 0 {
+		t.Errorf("expected the imported schedule to verify, got %+v", report)
+	}
+
+	// Moving every exam into one slot creates clashes
+	for i := range imported.Exams {
+		imported.Exams[i].SlotID = slots[0].ID
+	}
+	if report := VerifyScheduleExport(registrations, imported, nil); report.Valid {
+		t.Error("expected a clash to be reported")
+	}
+}
+
+func TestVerifyScheduleExportWithSettings(t *testing.T) {
+	_, registrations, _ := ParseRegistrations("student_id,course_id\ns1,c1\ns1,c2\n", nil)
+	halls, _ := ParseHalls("hall,capacity\nH1,5\n", nil)
+	slots, _ := GenerateSlots("2025-01-20", "2025-01-20", 2, []string{"09:00", "11:00"}, 60, nil, "UTC")
+	result := &ScheduleResult{Assignments: []*Assignment{
+		{CourseID: "c1", SlotID: slots[0].ID, SlotDateTime: slots[0].Start.Format(time.RFC3339), Halls: "H1", EnrolledCount: 1},
+		{CourseID: "c2", SlotID: slots[1].ID, SlotDateTime: slots[1].Start.Format(time.RFC3339), Halls: "H1", EnrolledCount: 1},
+	}}
+	export := NewScheduleExport(result, halls, slots, nil)
+
+	if report := VerifyScheduleExport(registrations, export, nil); !report.Valid {
+		t.Fatalf("expected the schedule to verify without settings, got %+v", report)
+	}
+
+	allowedSlots := map[CourseID]map[SlotID]bool{"c2": {slots[0].ID: true}}
+	report := VerifyScheduleExportWithSettings(registrations, export, allowedSlots, 180, nil)
+	if report.Valid || len(report.ConstraintViolations) != 2 {
+		t.Errorf("expected c2 outside its allowed slots and a minimum gap violation for s1, got %+v", report)
+	}
+}
+
+func TestReadScheduleJSON_Errors(t *testing.T) {
+	tests := map[string]string{
+		"missing version": `{"slots": [], "exams": []}`,
+		"newer version":   `{"version": 99}`,
+		"unknown slot":    `{"version": 1, "slots": [], "exams": [{"courseId": "c1", "slotId": "s9"}]}`,
+		"invalid JSON":    `{"version": 1,`,
+	}
+	for name, data := range tests {
+		if _, err := ReadScheduleJSON(strings.NewReader(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
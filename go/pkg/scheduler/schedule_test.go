@@ -1,6 +1,9 @@
 package scheduler
 
 import (
+	"bytes"
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -59,3 +62,60 @@ H2,2
 		t.Error("c4 was not assigned to any hall")
 	}
 }
+
+func TestRunSchedulingAttempts_SameSeedSameSchedule(t *testing.T) {
+	// Many courses of the same size and halls of the same capacity leave plenty of ties.
+	var regCSV strings.Builder
+	regCSV.WriteString("student_id,course_id\n")
+	for s := 0; s < 60; s++ {
+		for k := 0; k < 3; k++ {
+			fmt.Fprintf(&regCSV, "s%02d,c%02d\n", s, (s+7*k)%30)
+		}
+	}
+	hallsCSV := "hall,capacity\nH1,4\nH2,4\nH3,4\nH4,4\nH5,8\nH6,8\n"
+	slots, _ := GenerateSlots("2025-01-20", "2025-01-24", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+
+	run := func() string {
+		courses, _, _ := ParseRegistrations(regCSV.String(), nil)
+		halls, _ := ParseHalls(hallsCSV, nil)
+		result, err := RunSchedulingAttempts(5, 42, courses, halls, slots, nil, NewConflictGraph(courses), 0, PenaltyConfig{StudentProximityWeight: 1})
+		if err != nil {
+			t.Fatalf("RunSchedulingAttempts failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := WriteScheduleJSON(&buf, NewScheduleExport(result, halls, slots, nil)); err != nil {
+			t.Fatalf("WriteScheduleJSON failed: %v", err)
+		}
+		return buf.String()
+	}
+
+	first := run()
+	for i := 0; i < 5; i++ {
+		if again := run(); again != first {
+			t.Fatalf("run %d with the same seed gave a different schedule:\n%s\nfirst:\n%s", i+2, again, first)
+		}
+	}
+}
+
+func TestRunSchedulingAttempts_RecordedSeedRepeatsRun(t *testing.T) {
+	courses, _, _ := ParseRegistrations("student_id,course_id\ns1,c1\ns1,c2\ns2,c2\ns2,c3\ns3,c4\n", nil)
+	halls, _ := ParseHalls("hall,capacity\nH1,2\nH2,2\n", nil)
+	slots, _ := GenerateSlots("2025-01-20", "2025-01-21", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+
+	// Seed 0 picks a seed; the one recorded in the result must give the same schedule again.
+	first, err := RunSchedulingAttempts(5, 0, courses, halls, slots, nil, NewConflictGraph(courses), 0, PenaltyConfig{StudentProximityWeight: 1})
+	if err != nil {
+		t.Fatalf("RunSchedulingAttempts failed: %v", err)
+	}
+	if first.Seed == 0 {
+		t.Fatal("expected the picked seed to be recorded")
+	}
+	again, err := RunSchedulingAttempts(5, first.Seed, courses, halls, slots, nil, NewConflictGraph(courses), 0, PenaltyConfig{StudentProximityWeight: 1})
+	if err != nil {
+		t.Fatalf("RunSchedulingAttempts failed: %v", err)
+	}
+	want, _ := SerializeAssignments(first.Assignments)
+	if got, _ := SerializeAssignments(again.Assignments); got != want {
+		t.Errorf("expected seed %d to repeat the schedule\n%s\ngot\n%s", first.Seed, want, got)
+	}
+}
@@ -0,0 +1,426 @@
+package scheduler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SittingPlan records how split courses were divided into sittings.
+type SittingPlan struct {
+	Sittings map[CourseID][]CourseID  // Course to its sitting IDs, in order
+	Students map[CourseID][]StudentID // Sitting ID to the students who sit it
+}
+
+// SittingID returns the course ID used for the n-th sitting of a course, counting from 1.
+func SittingID(courseID CourseID, n int) CourseID {
+	return CourseID(fmt.Sprintf("%s/S%d", courseID, n))
+}
+
+// ParseCourseSittings parses the course sittings CSV data.
+// Required columns are course_id and sittings, the number of sittings to split the course into.
+// Rows that cannot be used are skipped; see ParseCourseSittingsWithDiagnostics to find out which.
+func ParseCourseSittings(csvData string) (map[CourseID]int, error) {
+	sittings, _, err := ParseCourseSittingsWithDiagnostics(csvData, false)
+	return sittings, err
+}
+
+// ParseCourseSittingsWithDiagnostics parses the course sittings CSV data like ParseCourseSittings
+// and also reports every skipped row. In strict mode any skipped row is an error.
+func ParseCourseSittingsWithDiagnostics(csvData string, strict bool) (map[CourseID]int, []Diagnostic, error) {
+	sittings := make(map[CourseID]int)
+	if csvData == "" {
+		return sittings, nil, nil
+	}
+
+	table, err := newCSVTable("course sittings", csvData, "course_id", "sittings")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for table.next() {
+		courseID := CourseID(table.field("course_id"))
+		if courseID == "" {
+			table.skip("course_id", "empty course ID")
+			continue
+		}
+		n, err := strconv.Atoi(table.field("sittings"))
+		if err != nil || n < 1 {
+			return nil, nil, fmt.Errorf("invalid number of sittings %q for course %s", table.field("sittings"), courseID)
+		}
+		sittings[courseID] = n
+	}
+
+	diagnostics, err := table.result(strict)
+	if err != nil {
+		return nil, diagnostics, err
+	}
+	return sittings, diagnostics, nil
+}
+
+// ParseSittings parses the sittings CSV written by SerializeSittings, with the columns student_id,
+// course_id and sitting_id, back into a plan. Sittings are listed in the order they first appear.
+// As the file is written by the scheduler, any row that cannot be used is an error, as is a
+// sitting ID used for two courses.
+func ParseSittings(csvData string) (*SittingPlan, error) {
+	plan := &SittingPlan{Sittings: make(map[CourseID][]CourseID), Students: make(map[CourseID][]StudentID)}
+	if csvData == "" {
+		return plan, nil
+	}
+
+	table, err := newCSVTable("sittings", csvData, "student_id", "course_id", "sitting_id")
+	if err != nil {
+		return nil, err
+	}
+
+	courseOf := make(map[CourseID]CourseID)
+	for table.next() {
+		studentID := StudentID(table.field("student_id"))
+		courseID := CourseID(table.field("course_id"))
+		sittingID := CourseID(table.field("sitting_id"))
+		if studentID == "" || courseID == "" || sittingID == "" {
+			table.skip("", "empty student, course or sitting ID")
+			continue
+		}
+		if course, ok := courseOf[sittingID]; !ok {
+			courseOf[sittingID] = courseID
+			plan.Sittings[courseID] = append(plan.Sittings[courseID], sittingID)
+		} else if course != courseID {
+			return nil, fmt.Errorf("sitting %s belongs to both %s and %s", sittingID, course, courseID)
+		}
+		plan.Students[sittingID] = append(plan.Students[sittingID], studentID)
+	}
+
+	if _, err := table.result(true); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Course returns the course a sitting belongs to, or the ID itself if it is not a sitting.
+func (p *SittingPlan) Course(id CourseID) CourseID {
+	if p != nil {
+		for courseID, ids := range p.Sittings {
+			for _, sittingID := range ids {
+				if sittingID == id {
+					return courseID
+				}
+			}
+		}
+	}
+	return id
+}
+
+// SplitSittings replaces each course with more than one sitting by one course per sitting, so
+// each sitting is coloured and seated as its own exam. Students are sorted by the other courses
+// they take and then by ID, and cut into sittings of near-equal size; students with the same
+// timetable end up in the same sitting, which keeps the sittings' clashes down. Sittings of a
+// course are kept in different slots. Allowed slots and course-level constraints of a split
+// course apply to each of its sittings. The returned constraints carry the plan for verification.
+func SplitSittings(
+	sittings map[CourseID]int,
+	courses map[CourseID]*Course,
+	allowedSlots map[CourseID]map[SlotID]bool,
+	constraints *Constraints,
+) (map[CourseID]*Course, map[CourseID]map[SlotID]bool, *Constraints, *SittingPlan, error) {
+	plan := &SittingPlan{Sittings: make(map[CourseID][]CourseID), Students: make(map[CourseID][]StudentID)}
+	if len(sittings) == 0 {
+		return courses, allowedSlots, constraints, plan, nil
+	}
+
+	studentCourses := make(map[StudentID][]string)
+	for courseID, course := range courses {
+		for _, studentID := range course.Enrollments {
+			studentCourses[studentID] = append(studentCourses[studentID], string(courseID))
+		}
+	}
+
+	split := make(map[CourseID]*Course, len(courses))
+	for courseID, course := range courses {
+		n := min(sittings[courseID], len(course.Enrollments))
+		if n <= 1 {
+			split[courseID] = course
+			continue
+		}
+
+		students := sortedStudents(course.Enrollments)
+		signature := make(map[StudentID]string, len(students))
+		for _, studentID := range students {
+			var others []string
+			for _, c := range studentCourses[studentID] {
+				if c != string(courseID) {
+					others = append(others, c)
+				}
+			}
+			sort.Strings(others)
+			signature[studentID] = strings.Join(others, ";")
+		}
+		sort.SliceStable(students, func(i, j int) bool {
+			return signature[students[i]] < signature[students[j]]
+		})
+
+		for i := 0; i < n; i++ {
+			sittingID := SittingID(courseID, i+1)
+			part := students[i*len(students)/n : (i+1)*len(students)/n]
+			split[sittingID] = &Course{ID: sittingID, Enrollments: part}
+			plan.Sittings[courseID] = append(plan.Sittings[courseID], sittingID)
+			plan.Students[sittingID] = part
+		}
+	}
+	if len(plan.Sittings) == 0 {
+		return courses, allowedSlots, constraints, plan, nil
+	}
+	splitAllowed, splitConstraints := applySittings(plan, allowedSlots, constraints)
+	return split, splitAllowed, splitConstraints, plan, nil
+}
+
+// SplitSittingsWithPlan splits courses into the sittings of an existing plan, such as the one
+// read back with ParseSittings for a published schedule, instead of dividing the students anew.
+// Students keep their sitting; students new to a split course join its smallest sitting, and
+// students who left it are dropped. Sittings left without students and courses no longer in
+// courses are dropped from the returned plan.
+func SplitSittingsWithPlan(
+	plan *SittingPlan,
+	courses map[CourseID]*Course,
+	allowedSlots map[CourseID]map[SlotID]bool,
+	constraints *Constraints,
+) (map[CourseID]*Course, map[CourseID]map[SlotID]bool, *Constraints, *SittingPlan, error) {
+	updated := &SittingPlan{Sittings: make(map[CourseID][]CourseID), Students: make(map[CourseID][]StudentID)}
+	if plan == nil || len(plan.Sittings) == 0 {
+		return courses, allowedSlots, constraints, updated, nil
+	}
+
+	split := make(map[CourseID]*Course, len(courses))
+	for courseID, course := range courses {
+		ids, ok := plan.Sittings[courseID]
+		if !ok {
+			split[courseID] = course
+			continue
+		}
+
+		enrolled := make(map[StudentID]bool, len(course.Enrollments))
+		for _, studentID := range course.Enrollments {
+			enrolled[studentID] = true
+		}
+		placed := make(map[StudentID]bool, len(course.Enrollments))
+		students := make([][]StudentID, len(ids))
+		for i, id := range ids {
+			for _, studentID := range plan.Students[id] {
+				if enrolled[studentID] && !placed[studentID] {
+					placed[studentID] = true
+					students[i] = append(students[i], studentID)
+				}
+			}
+		}
+		for _, studentID := range sortedStudents(course.Enrollments) {
+			if placed[studentID] {
+				continue
+			}
+			smallest := 0
+			for i := range students {
+				if len(students[i]) < len(students[smallest]) {
+					smallest = i
+				}
+			}
+			students[smallest] = append(students[smallest], studentID)
+		}
+
+		for i, id := range ids {
+			if len(students[i]) == 0 {
+				continue
+			}
+			split[id] = &Course{ID: id, Enrollments: students[i]}
+			updated.Sittings[courseID] = append(updated.Sittings[courseID], id)
+			updated.Students[id] = students[i]
+		}
+	}
+	if len(updated.Sittings) == 0 {
+		return courses, allowedSlots, constraints, updated, nil
+	}
+	splitAllowed, splitConstraints := applySittings(updated, allowedSlots, constraints)
+	return split, splitAllowed, splitConstraints, updated, nil
+}
+
+// applySittings gives every sitting the allowed slots of its course and splits the constraints.
+func applySittings(plan *SittingPlan, allowedSlots map[CourseID]map[SlotID]bool, constraints *Constraints) (map[CourseID]map[SlotID]bool, *Constraints) {
+	splitAllowed := make(map[CourseID]map[SlotID]bool, len(allowedSlots))
+	for courseID, allowed := range allowedSlots {
+		if ids, ok := plan.Sittings[courseID]; ok {
+			for _, id := range ids {
+				splitAllowed[id] = allowed
+			}
+			continue
+		}
+		splitAllowed[courseID] = allowed
+	}
+	return splitAllowed, constraints.withSittings(plan)
+}
+
+// withSittings returns a copy of the constraints in which the course-level rules of split courses
+// apply to each sitting, and the sittings of a course must be in different slots.
+func (c *Constraints) withSittings(plan *SittingPlan) *Constraints {
+	out := &Constraints{}
+	if c != nil {
+		*out = *c
+	}
+	out.Sittings = plan
+
+	expand := func(courseID CourseID) []CourseID {
+		if ids, ok := plan.Sittings[courseID]; ok {
+			return ids
+		}
+		return []CourseID{courseID}
+	}
+
+	out.Relations = nil
+	if c != nil {
+		for _, r := range c.Relations {
+			for _, a := range expand(r.A) {
+				for _, b := range expand(r.B) {
+					out.Relations = append(out.Relations, CourseRelation{A: a, Kind: r.Kind, B: b, MinGapMinutes: r.MinGapMinutes})
+				}
+			}
+		}
+	}
+	courseIDs := make([]CourseID, 0, len(plan.Sittings))
+	for courseID := range plan.Sittings {
+		courseIDs = append(courseIDs, courseID)
+	}
+	sort.Slice(courseIDs, func(i, j int) bool { return courseIDs[i] < courseIDs[j] })
+	for _, courseID := range courseIDs {
+		ids := plan.Sittings[courseID]
+		for i := range ids {
+			for j := i + 1; j < len(ids); j++ {
+				out.Relations = append(out.Relations, CourseRelation{A: ids[i], Kind: RelationDifferentSlot, B: ids[j]})
+			}
+		}
+	}
+
+	out.Relations = uniqueRelations(out.Relations)
+
+	if c == nil {
+		return out
+	}
+
+	out.Forbidden = nil
+	for _, f := range c.Forbidden {
+		if f.CourseID == "" {
+			out.Forbidden = append(out.Forbidden, f)
+			continue
+		}
+		for _, id := range expand(f.CourseID) {
+			f.CourseID = id
+			out.Forbidden = append(out.Forbidden, f)
+		}
+	}
+
+	if c.Requirements != nil {
+		out.Requirements = make(map[CourseID][]string, len(c.Requirements))
+		for courseID, features := range c.Requirements {
+			for _, id := range expand(courseID) {
+				out.Requirements[id] = features
+			}
+		}
+	}
+	return out
+}
+
+// uniqueRelations drops repeated relations, which appear when constraints are split twice.
+func uniqueRelations(relations []CourseRelation) []CourseRelation {
+	seen := make(map[CourseRelation]bool, len(relations))
+	out := relations[:0]
+	for _, r := range relations {
+		if !seen[r] {
+			seen[r] = true
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// SerializeSittings writes the sitting of every student of a split course as CSV with the
+// columns student_id, course_id and sitting_id.
+func SerializeSittings(plan *SittingPlan) (string, error) {
+	var sb strings.Builder
+	writer := csv.NewWriter(&sb)
+	if err := writer.Write([]string{"student_id", "course_id", "sitting_id"}); err != nil {
+		return "", err
+	}
+
+	courseIDs := make([]CourseID, 0, len(plan.Sittings))
+	for courseID := range plan.Sittings {
+		courseIDs = append(courseIDs, courseID)
+	}
+	sort.Slice(courseIDs, func(i, j int) bool { return courseIDs[i] < courseIDs[j] })
+
+	for _, courseID := range courseIDs {
+		for _, sittingID := range plan.Sittings[courseID] {
+			students := sortedStudents(plan.Students[sittingID])
+			for _, studentID := range students {
+				if err := writer.Write([]string{string(studentID), string(courseID), string(sittingID)}); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// assignSittings maps registrations of split courses to the student's sitting, for verification.
+// A student of a split course must be in exactly one of its sittings; anything else is reported.
+func assignSittings(report *ValidationReport, registrations []Registration, plan *SittingPlan) []Registration {
+	if plan == nil || len(plan.Sittings) == 0 {
+		return registrations
+	}
+
+	sittingOf := make(map[CourseID]map[StudentID][]CourseID)
+	for courseID, ids := range plan.Sittings {
+		sittingOf[courseID] = make(map[StudentID][]CourseID)
+		for _, id := range ids {
+			for _, studentID := range plan.Students[id] {
+				sittingOf[courseID][studentID] = append(sittingOf[courseID][studentID], id)
+			}
+		}
+	}
+
+	registered := make(map[Registration]bool, len(registrations))
+	mapped := make([]Registration, 0, len(registrations))
+	for _, reg := range registrations {
+		registered[reg] = true
+		students, ok := sittingOf[reg.CourseID]
+		if !ok {
+			mapped = append(mapped, reg)
+			continue
+		}
+		ids := students[reg.StudentID]
+		switch len(ids) {
+		case 0:
+			report.ConstraintViolations = append(report.ConstraintViolations,
+				fmt.Sprintf("student %s of course %s is not in any sitting", reg.StudentID, reg.CourseID))
+		case 1:
+			mapped = append(mapped, Registration{StudentID: reg.StudentID, CourseID: ids[0]})
+		default:
+			report.ConstraintViolations = append(report.ConstraintViolations,
+				fmt.Sprintf("student %s of course %s is in %d sittings", reg.StudentID, reg.CourseID, len(ids)))
+			mapped = append(mapped, Registration{StudentID: reg.StudentID, CourseID: ids[0]})
+		}
+	}
+
+	// Students placed in a sitting of a course they are not registered for
+	for courseID, students := range sittingOf {
+		for studentID, ids := range students {
+			if !registered[Registration{StudentID: studentID, CourseID: courseID}] {
+				report.ConstraintViolations = append(report.ConstraintViolations,
+					fmt.Sprintf("student %s is in sitting %s but not registered for course %s", studentID, ids[0], courseID))
+			}
+		}
+	}
+	return mapped
+}
@@ -0,0 +1,213 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseCourseSittings(t *testing.T) {
+	sittings, err := ParseCourseSittings(`course_id,sittings
+INTRO101,3
+`)
+	if err != nil {
+		t.Fatalf("ParseCourseSittings failed: %v", err)
+	}
+	if sittings["INTRO101"] != 3 {
+		t.Errorf("expected 3 sittings for INTRO101, got %v", sittings)
+	}
+	if _, err := ParseCourseSittings("course_id,sittings\nINTRO101,0\n"); err == nil {
+		t.Error("expected an error for zero sittings")
+	}
+}
+
+func TestSplitSittings(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("student_id,course_id\n")
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&sb, "s%02d,INTRO101\n", i)
+	}
+	// Students s01, s04, s07 and s10 also take MA101; they should share a sitting.
+	for _, s := range []string{"s01", "s04", "s07", "s10"} {
+		fmt.Fprintf(&sb, "%s,MA101\n", s)
+	}
+	courses, regs, _ := ParseRegistrations(sb.String(), nil)
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-07", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+
+	split, allowed, constraints, plan, err := SplitSittings(map[CourseID]int{"INTRO101": 2}, courses, nil, nil)
+	if err != nil {
+		t.Fatalf("SplitSittings failed: %v", err)
+	}
+	if _, ok := split["INTRO101"]; ok {
+		t.Fatal("expected INTRO101 to be replaced by its sittings")
+	}
+	if len(plan.Sittings["INTRO101"]) != 2 {
+		t.Fatalf("expected 2 sittings, got %v", plan.Sittings)
+	}
+
+	seen := make(map[StudentID]int)
+	for _, id := range plan.Sittings["INTRO101"] {
+		if n := len(split[id].Enrollments); n != 5 {
+			t.Errorf("expected sitting %s to have 5 students, got %d", id, n)
+		}
+		for _, s := range plan.Students[id] {
+			seen[s]++
+		}
+	}
+	if len(seen) != 10 {
+		t.Errorf("expected all 10 students to be placed, got %d", len(seen))
+	}
+	for s, n := range seen {
+		if n != 1 {
+			t.Errorf("student %s is in %d sittings", s, n)
+		}
+	}
+	// The 6 students without MA101 sort first, so the second sitting holds only MA101 students.
+	graph := NewConflictGraph(split)
+	if graph.AdjMatrix[graph.CourseIndex[SittingID("INTRO101", 1)]][graph.CourseIndex["MA101"]] != 0 {
+		t.Error("expected the first sitting to have no students in common with MA101")
+	}
+
+	halls := []*Hall{{ID: "H1", Capacity: 10}}
+	result, err := RunSchedulingAttemptsWithConstraints(5, 42, split, halls, slots, allowed, graph, 0, PenaltyConfig{}, constraints)
+	if err != nil {
+		t.Fatalf("RunSchedulingAttempts failed: %v", err)
+	}
+	slotOf := make(map[CourseID]SlotID)
+	for _, a := range result.Assignments {
+		slotOf[a.CourseID] = a.SlotID
+	}
+	if slotOf["INTRO101/S1"] == slotOf["INTRO101/S2"] {
+		t.Error("expected the sittings to be in different slots")
+	}
+
+	scheduleCSV, _ := SerializeAssignments(result.Assignments)
+	report, err := VerifyScheduleWithConstraints(regs, scheduleCSV, halls, slots, &Constraints{Sittings: plan})
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithConstraints failed: %v", err)
+	}
+	if !report.Valid {
+		t.Errorf("expected the split schedule to verify, got %+v", report)
+	}
+
+	sittingsCSV, err := SerializeSittings(plan)
+	if err != nil {
+		t.Fatalf("SerializeSittings failed: %v", err)
+	}
+	if lines := strings.Count(sittingsCSV, "\n"); lines != 11 {
+		t.Errorf("expected a header and 10 rows, got %d lines", lines)
+	}
+
+	parsed, err := ParseSittings(sittingsCSV)
+	if err != nil {
+		t.Fatalf("ParseSittings failed: %v", err)
+	}
+	if again, _ := SerializeSittings(parsed); again != sittingsCSV {
+		t.Errorf("expected the sittings to round-trip, got:\n%s", again)
+	}
+	report, err = VerifyScheduleWithConstraints(regs, scheduleCSV, halls, slots, &Constraints{Sittings: parsed})
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithConstraints failed: %v", err)
+	}
+	if !report.Valid || len(report.Unassigned) != 0 {
+		t.Errorf("expected the split schedule to verify with the parsed sittings, got %+v", report)
+	}
+}
+
+func TestParseSittings(t *testing.T) {
+	plan, err := ParseSittings(`student_id,course_id,sitting_id
+s1,BIG,BIG/S1
+s2,BIG,BIG/S2
+s3,BIG,BIG/S1
+`)
+	if err != nil {
+		t.Fatalf("ParseSittings failed: %v", err)
+	}
+	if ids := plan.Sittings["BIG"]; len(ids) != 2 || ids[0] != "BIG/S1" || ids[1] != "BIG/S2" {
+		t.Errorf("unexpected sittings: %v", plan.Sittings)
+	}
+	if len(plan.Students["BIG/S1"]) != 2 || plan.Course("BIG/S2") != "BIG" || plan.Course("MA101") != "MA101" {
+		t.Errorf("unexpected plan: %+v", plan)
+	}
+
+	if _, err := ParseSittings("student_id,course_id,sitting_id\ns1,BIG,X/S1\ns2,SMALL,X/S1\n"); err == nil {
+		t.Error("expected an error for a sitting of two courses")
+	}
+	if _, err := ParseSittings("student_id,course_id\ns1,BIG\n"); err == nil {
+		t.Error("expected an error for a missing sitting_id column")
+	}
+}
+
+func TestRepairSchedule_KeepsSittings(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-07", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	halls := []*Hall{{ID: "H1", Capacity: 10}}
+	published := []*Assignment{
+		{CourseID: "BIG/S1", SlotID: slots[0].ID, Halls: "H1", EnrolledCount: 2},
+		{CourseID: "BIG/S2", SlotID: slots[1].ID, Halls: "H1", EnrolledCount: 2},
+	}
+	plan, _ := ParseSittings(`student_id,course_id,sitting_id
+s1,BIG,BIG/S1
+s2,BIG,BIG/S1
+s3,BIG,BIG/S2
+s4,BIG,BIG/S2
+`)
+
+	// s2 has dropped BIG and s5 joined it.
+	courses, regs, _ := ParseRegistrations(`student_id,course_id
+s1,BIG
+s3,BIG
+s4,BIG
+s5,BIG
+`, nil)
+	split, allowed, constraints, updated, err := SplitSittingsWithPlan(plan, courses, nil, nil)
+	if err != nil {
+		t.Fatalf("SplitSittingsWithPlan failed: %v", err)
+	}
+	if got := updated.Students["BIG/S1"]; len(got) != 2 || got[0] != "s1" || got[1] != "s5" {
+		t.Errorf("expected s5 to join the smaller sitting, got %v", updated.Students)
+	}
+
+	result, changes, err := RepairScheduleWithConstraints(published, split, halls, slots, allowed, NewConflictGraph(split), 0, PenaltyConfig{}, constraints)
+	if err != nil {
+		t.Fatalf("RepairSchedule failed: %v", err)
+	}
+	if len(result.Assignments) != 2 || len(changes) != 0 {
+		t.Errorf("expected both sittings to stay put, got %d rows and changes %+v", len(result.Assignments), changes)
+	}
+
+	scheduleCSV, _ := SerializeAssignments(result.Assignments)
+	report, err := VerifyScheduleWithConstraints(regs, scheduleCSV, halls, slots, &Constraints{Sittings: updated})
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithConstraints failed: %v", err)
+	}
+	if !report.Valid || len(report.Unassigned) != 0 {
+		t.Errorf("expected the repaired schedule to verify, got %+v", report)
+	}
+}
+
+func TestVerifyScheduleWithConstraints_Sittings(t *testing.T) {
+	_, regs, _ := ParseRegistrations(`student_id,course_id
+s1,BIG
+s2,BIG
+s3,BIG
+`, nil)
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-06", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	scheduleCSV := `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+BIG/S1,2025-01-06T09:00Z#1,2025-01-06T09:00:00Z,H1,2,
+BIG/S2,2025-01-06T14:00Z#2,2025-01-06T14:00:00Z,H1,1,
+`
+	halls := []*Hall{{ID: "H1", Capacity: 10}}
+	// s1 is in both sittings and s3 in neither.
+	plan := &SittingPlan{
+		Sittings: map[CourseID][]CourseID{"BIG": {"BIG/S1", "BIG/S2"}},
+		Students: map[CourseID][]StudentID{"BIG/S1": {"s1", "s2"}, "BIG/S2": {"s1"}},
+	}
+
+	report, err := VerifyScheduleWithConstraints(regs, scheduleCSV, halls, slots, &Constraints{Sittings: plan})
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithConstraints failed: %v", err)
+	}
+	if report.Valid || len(report.ConstraintViolations) != 2 {
+		t.Errorf("expected 2 sitting violations, got %+v", report.ConstraintViolations)
+	}
+}
@@ -2,16 +2,18 @@ package scheduler
 
 import (
 	"fmt"
+	"sort"
 	"time"
 )
 
 // GenerateSlots creates a list of exam slots based on the provided parameters.
+// Slot times are wall-clock times in the given IANA timezone (UTC if empty), so a 09:00 slot
+// starts at 09:00 local time even on a daylight saving changeover day.
 func GenerateSlots(startDate, endDate string, slotsPerDay int, slotTimes []string, slotDuration int, holidays []string, timezone string) ([]*Slot, error) {
 	var slots []*Slot
 	loc, err := time.LoadLocation(timezone)
 	if err != nil {
-		// Fallback to UTC if timezone is invalid
-		loc = time.UTC
+		return nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
 	}
 
 	start, err := time.ParseInLocation("2006-01-02", startDate, loc)
@@ -24,39 +26,40 @@ func GenerateSlots(startDate, endDate string, slotsPerDay int, slotTimes []strin
 		return nil, fmt.Errorf("invalid end date: %w", err)
 	}
 
+	// Wall-clock start of each slot, in minutes after midnight
+	var startMinutes []int
+	if len(slotTimes) > 0 {
+		for _, st := range slotTimes {
+			t, err := time.Parse("15:04", st)
+			if err != nil {
+				return nil, fmt.Errorf("invalid slot time '%s': %w", st, err)
+			}
+			startMinutes = append(startMinutes, t.Hour()*60+t.Minute())
+		}
+	} else {
+		// Generate evenly spaced times
+		dayStart := 9 * 60 // Default start time 09:00
+		for i := 0; i < slotsPerDay; i++ {
+			startMinutes = append(startMinutes, dayStart+i*slotDuration)
+		}
+	}
+
 	holidayMap := make(map[string]bool)
 	for _, h := range holidays {
 		holidayMap[h] = true
 	}
 
 	dayIndex := 0
-	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+	for d := start; !d.After(end); d = time.Date(d.Year(), d.Month(), d.Day()+1, 0, 0, 0, 0, loc) {
 		dateStr := d.Format("2006-01-02")
 		if holidayMap[dateStr] || d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
 			continue
 		}
 
-		var timesToUse []time.Time
-		if len(slotTimes) > 0 {
-			for _, st := range slotTimes {
-				t, err := time.Parse("15:04", st)
-				if err != nil {
-					return nil, fmt.Errorf("invalid slot time '%s': %w", st, err)
-				}
-				timesToUse = append(timesToUse, d.Add(time.Hour*time.Duration(t.Hour())+time.Minute*time.Duration(t.Minute())))
-			}
-		} else {
-			// Generate evenly spaced times
-			dayStart, _ := time.ParseInLocation("15:04", "09:00", loc) // Default start time
-			for i := 0; i < slotsPerDay; i++ {
-				timesToUse = append(timesToUse, d.Add(time.Hour*time.Duration(dayStart.Hour())+time.Minute*time.Duration(dayStart.Minute())).Add(time.Duration(i*slotDuration)*time.Minute))
-			}
-		}
-
-		for i, t := range timesToUse {
-			slotID := SlotID(fmt.Sprintf("%sT%sZ#%d", d.Format("2006-01-02"), t.Format("15:04"), i+1))
+		for i, minutes := range startMinutes {
+			t := time.Date(d.Year(), d.Month(), d.Day(), 0, minutes, 0, 0, loc)
 			slot := &Slot{
-				ID:         slotID,
+				ID:         slotIDFor(t, i),
 				Start:      t,
 				End:        t.Add(time.Duration(slotDuration) * time.Minute),
 				DayIndex:   dayIndex,
@@ -69,3 +72,134 @@ func GenerateSlots(startDate, endDate string, slotsPerDay int, slotTimes []strin
 
 	return slots, nil
 }
+
+// slotIDFor builds the ID of the slot starting at t with the given index within its day.
+// The ID holds the local wall-clock time and its UTC offset, e.g. 2025-01-06T09:00Z#1 in UTC
+// or 2025-03-30T09:00+02:00#1 in Europe/Berlin.
+func slotIDFor(t time.Time, indexInDay int) SlotID {
+	return SlotID(fmt.Sprintf("%s#%d", t.Format("2006-01-02T15:04Z07:00"), indexInDay+1))
+}
+
+// slotTimeLayouts are the accepted formats for start and end times in the slots CSV.
+var slotTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04"}
+
+// parseSlotTime parses a slot start or end time, in loc unless it carries its own offset.
+func parseSlotTime(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range slotTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected YYYY-MM-DD HH:MM or RFC 3339", s)
+}
+
+// ParseSlots parses an explicit list of slots from CSV data, as an alternative to GenerateSlots.
+// Required columns are start and end; id and day are optional. Times are read in the given
+// timezone unless they include an offset. Slots are returned in start order. Slots with the same
+// day label share a DayIndex; without a label the calendar date is the day. IndexInDay counts
+// slots within each day, and a missing ID is generated as GenerateSlots would. Overlapping slots
+// and duplicate IDs are errors. Rows that cannot be read, with an invalid start or end or ending
+// before they start, are skipped; see ParseSlotsWithDiagnostics to find out which.
+func ParseSlots(csvData string, timezone string) ([]*Slot, error) {
+	slots, _, err := ParseSlotsWithDiagnostics(csvData, timezone, false)
+	return slots, err
+}
+
+// ParseSlotsWithDiagnostics parses the slots CSV data like ParseSlots and also reports every
+// skipped row. In strict mode any skipped row is an error.
+func ParseSlotsWithDiagnostics(csvData string, timezone string, strict bool) ([]*Slot, []Diagnostic, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+
+	table, err := newCSVTable("slots", csvData, "start", "end")
+	if err != nil {
+		return nil, nil, err
+	}
+	idColumn := "id"
+	if !table.has(idColumn) {
+		idColumn = "slot_id"
+	}
+
+	var slots []*Slot
+	dayLabels := make(map[*Slot]string)
+	for table.next() {
+		id := table.field(idColumn)
+		if table.field("start") == "" && table.field("end") == "" {
+			table.diags.add(SeverityWarning, table.line, "", "empty row is ignored")
+			continue
+		}
+
+		start, err := parseSlotTime(table.field("start"), loc)
+		if err != nil {
+			table.skip("start", "invalid start for slot %q: %v", id, err)
+			continue
+		}
+		end, err := parseSlotTime(table.field("end"), loc)
+		if err != nil {
+			table.skip("end", "invalid end for slot %q: %v", id, err)
+			continue
+		}
+		if !end.After(start) {
+			table.skip("end", "slot %q ends before it starts", id)
+			continue
+		}
+
+		slot := &Slot{ID: SlotID(id), Start: start, End: end}
+		label := table.field("day")
+		if label == "" {
+			label = start.Format("2006-01-02")
+		}
+		dayLabels[slot] = label
+		slots = append(slots, slot)
+	}
+	if len(slots) == 0 {
+		return nil, table.diags.items, fmt.Errorf("no slots defined")
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].Start.Before(slots[j].Start)
+	})
+
+	days := make(map[string]int)
+	perDay := make(map[string]int)
+	seen := make(map[SlotID]bool)
+	for i, slot := range slots {
+		if i > 0 && slot.Start.Before(slots[i-1].End) {
+			return nil, nil, fmt.Errorf("slot %s overlaps slot %s", describeSlot(slot), describeSlot(slots[i-1]))
+		}
+
+		label := dayLabels[slot]
+		if index, ok := days[label]; !ok {
+			days[label] = len(days)
+		} else if index != len(days)-1 {
+			return nil, nil, fmt.Errorf("slot %s belongs to day %q, which is interrupted by another day", describeSlot(slot), label)
+		}
+		slot.DayIndex = days[label]
+		slot.IndexInDay = perDay[label]
+		perDay[label]++
+
+		if slot.ID == "" {
+			slot.ID = slotIDFor(slot.Start, slot.IndexInDay)
+		}
+		if seen[slot.ID] {
+			return nil, nil, fmt.Errorf("duplicate slot ID %s", slot.ID)
+		}
+		seen[slot.ID] = true
+	}
+
+	diagnostics, err := table.result(strict)
+	if err != nil {
+		return nil, diagnostics, err
+	}
+	return slots, diagnostics, nil
+}
+
+// describeSlot names a slot in error messages, falling back to its start time if it has no ID yet.
+func describeSlot(slot *Slot) string {
+	if slot.ID != "" {
+		return string(slot.ID)
+	}
+	return slot.Start.Format("2006-01-02 15:04")
+}
@@ -1,7 +1,9 @@
 package scheduler
 
 import (
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGenerateSlots(t *testing.T) {
@@ -37,3 +39,219 @@ func TestGenerateSlots(t *testing.T) {
 		t.Errorf("unexpected slot ID for third slot: %s", slots[2].ID)
 	}
 }
+
+func TestParseSlots(t *testing.T) {
+	slots, err := ParseSlots(`id,start,end,day
+,2025-01-06 14:00,2025-01-06 17:00,
+MON-AM,2025-01-06 09:30,2025-01-06 12:00,
+,2025-01-07 09:00,2025-01-07 12:00,
+SAT,2025-01-11 10:00,2025-01-11 11:30,Saturday
+`, "UTC")
+	if err != nil {
+		t.Fatalf("ParseSlots failed: %v", err)
+	}
+	if len(slots) != 4 {
+		t.Fatalf("expected 4 slots, got %d", len(slots))
+	}
+
+	expected := []struct {
+		id         SlotID
+		dayIndex   int
+		indexInDay int
+	}{
+		{"MON-AM", 0, 0},
+		{"2025-01-06T14:00Z#2", 0, 1},
+		{"2025-01-07T09:00Z#1", 1, 0},
+		{"SAT", 2, 0},
+	}
+	for i, e := range expected {
+		if slots[i].ID != e.id || slots[i].DayIndex != e.dayIndex || slots[i].IndexInDay != e.indexInDay {
+			t.Errorf("slot %d: expected %+v, got %s day %d index %d", i, e, slots[i].ID, slots[i].DayIndex, slots[i].IndexInDay)
+		}
+	}
+	if d := slots[3].End.Sub(slots[3].Start); d.Minutes() != 90 {
+		t.Errorf("expected a 90 minute Saturday session, got %v", d)
+	}
+}
+
+func TestParseSlots_Errors(t *testing.T) {
+	cases := map[string]string{
+		"overlap": `id,start,end
+A,2025-01-06 09:00,2025-01-06 12:00
+B,2025-01-06 11:00,2025-01-06 13:00
+`,
+		"end before start": `id,start,end
+A,2025-01-06 12:00,2025-01-06 09:00
+`,
+		"duplicate id": `id,start,end
+A,2025-01-06 09:00,2025-01-06 12:00
+A,2025-01-07 09:00,2025-01-07 12:00
+`,
+		"bad time": `id,start,end
+A,06/01/2025 09:00,2025-01-06 12:00
+`,
+		"interrupted day": `id,start,end,day
+A,2025-01-06 09:00,2025-01-06 12:00,D1
+B,2025-01-06 13:00,2025-01-06 15:00,D2
+C,2025-01-06 16:00,2025-01-06 18:00,D1
+`,
+		"missing end": `id,start
+A,2025-01-06 09:00
+`,
+	}
+	for name, csvData := range cases {
+		if _, err := ParseSlots(csvData, "UTC"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestGenerateSlots_InvalidTimezone(t *testing.T) {
+	if _, err := GenerateSlots("2025-01-06", "2025-01-07", 2, []string{"09:00", "14:00"}, 180, nil, "Mars/Olympus_Mons"); err == nil {
+		t.Error("expected an error for an invalid timezone")
+	}
+}
+
+func TestGenerateSlots_DSTChangeover(t *testing.T) {
+	// Europe/Berlin moves from +01:00 to +02:00 on Sunday 2025-03-30 and back on Sunday 2025-10-26.
+	// Weekends are skipped, so check the working days either side of each changeover.
+	cases := []struct {
+		name, start, end string
+		before, after    string
+	}{
+		{"spring", "2025-03-28", "2025-03-31", "2025-03-28T09:00+01:00#1", "2025-03-31T09:00+02:00#1"},
+		{"autumn", "2025-10-24", "2025-10-27", "2025-10-24T09:00+02:00#1", "2025-10-27T09:00+01:00#1"},
+	}
+	for _, c := range cases {
+		slots, err := GenerateSlots(c.start, c.end, 2, []string{"09:00", "14:00"}, 180, nil, "Europe/Berlin")
+		if err != nil {
+			t.Fatalf("%s: GenerateSlots failed: %v", c.name, err)
+		}
+		if len(slots) != 4 {
+			t.Fatalf("%s: expected 4 slots, got %d", c.name, len(slots))
+		}
+		if slots[0].ID != SlotID(c.before) || slots[2].ID != SlotID(c.after) {
+			t.Errorf("%s: unexpected slot IDs %s and %s", c.name, slots[0].ID, slots[2].ID)
+		}
+		for _, slot := range slots {
+			if h := slot.Start.Hour(); h != 9 && h != 14 {
+				t.Errorf("%s: slot %s starts at local hour %d", c.name, slot.ID, h)
+			}
+			if slot.End.Sub(slot.Start) != 180*time.Minute {
+				t.Errorf("%s: slot %s lasts %v", c.name, slot.ID, slot.End.Sub(slot.Start))
+			}
+		}
+	}
+}
+
+func TestGenerateSlots_DSTOnWeekday(t *testing.T) {
+	// Africa/Cairo springs forward at midnight starting Friday 2024-04-26 and falls back at the
+	// end of Thursday 2024-10-31, so slots fall on the changeover days themselves.
+	cases := []struct {
+		name, start, end string
+		ids              []SlotID
+	}{
+		{"spring", "2024-04-25", "2024-04-26", []SlotID{
+			"2024-04-25T09:00+02:00#1", "2024-04-25T14:00+02:00#2",
+			"2024-04-26T09:00+03:00#1", "2024-04-26T14:00+03:00#2",
+		}},
+		{"autumn", "2024-10-31", "2024-11-01", []SlotID{
+			"2024-10-31T09:00+03:00#1", "2024-10-31T14:00+03:00#2",
+			"2024-11-01T09:00+02:00#1", "2024-11-01T14:00+02:00#2",
+		}},
+	}
+	for _, c := range cases {
+		slots, err := GenerateSlots(c.start, c.end, 2, []string{"09:00", "14:00"}, 180, nil, "Africa/Cairo")
+		if err != nil {
+			t.Fatalf("%s: GenerateSlots failed: %v", c.name, err)
+		}
+		if len(slots) != len(c.ids) {
+			t.Fatalf("%s: expected %d slots, got %d", c.name, len(c.ids), len(slots))
+		}
+		for i, slot := range slots {
+			if slot.ID != c.ids[i] {
+				t.Errorf("%s: expected slot ID %s, got %s", c.name, c.ids[i], slot.ID)
+			}
+			wantClock, wantOffset := string(c.ids[i][11:16]), string(c.ids[i][16:22])
+			if got := slot.Start.Format("15:04"); got != wantClock {
+				t.Errorf("%s: slot %s starts at %s local time, expected %s", c.name, slot.ID, got, wantClock)
+			}
+			if got := slot.Start.Format("Z07:00"); got != wantOffset {
+				t.Errorf("%s: slot %s starts at offset %s, expected %s", c.name, slot.ID, got, wantOffset)
+			}
+			if slot.End.Sub(slot.Start) != 180*time.Minute {
+				t.Errorf("%s: slot %s lasts %v", c.name, slot.ID, slot.End.Sub(slot.Start))
+			}
+		}
+	}
+}
+
+func TestGenerateSlots_DSTElapsedTime(t *testing.T) {
+	// America/New_York springs forward on Sunday 2025-03-09, between the Friday and Monday slots,
+	// so the two 09:00 starts are one hour less than three days apart.
+	slots, err := GenerateSlots("2025-03-07", "2025-03-10", 0, []string{"09:00"}, 60, nil, "America/New_York")
+	if err != nil {
+		t.Fatalf("GenerateSlots failed: %v", err)
+	}
+	if len(slots) != 2 {
+		t.Fatalf("expected 2 slots, got %d", len(slots))
+	}
+	if got := slots[1].Start.Sub(slots[0].Start); got != 3*24*time.Hour-time.Hour {
+		t.Errorf("expected 71 hours between Friday and Monday 09:00 across the changeover, got %v", got)
+	}
+	if slots[1].ID != "2025-03-10T09:00-04:00#1" {
+		t.Errorf("unexpected slot ID %s", slots[1].ID)
+	}
+}
+
+func TestParseSlotsWithDiagnostics(t *testing.T) {
+	csvData := `id,start,end
+A,2025-01-06 09:00,2025-01-06 12:00
+,,
+B,2025-01-06 14:00,2025-01-06 17:00
+`
+	slots, diags, err := ParseSlotsWithDiagnostics(csvData, "UTC", true)
+	if err != nil {
+		t.Fatalf("ParseSlotsWithDiagnostics failed: %v", err)
+	}
+	if len(slots) != 2 {
+		t.Errorf("expected 2 slots, got %d", len(slots))
+	}
+	if len(diags) != 1 || diags[0].Severity != SeverityWarning || diags[0].Line != 3 {
+		t.Errorf("expected a warning for the empty row, got %v", diags)
+	}
+}
+
+func TestParseSlotsWithDiagnostics_BadRows(t *testing.T) {
+	csvData := `id,start,end
+A,2025-01-06 09:00,2025-01-06 12:00
+B,06/01/2025 14:00,2025-01-06 17:00
+C,2025-01-07 09:00,noon
+D,2025-01-07 14:00,2025-01-07 13:00
+E,2025-01-08 09:00,2025-01-08 12:00
+`
+	slots, diags, err := ParseSlotsWithDiagnostics(csvData, "UTC", false)
+	if err != nil {
+		t.Fatalf("ParseSlotsWithDiagnostics failed: %v", err)
+	}
+	if len(slots) != 2 || slots[0].ID != "A" || slots[1].ID != "E" {
+		t.Errorf("expected slots A and E, got %v", slots)
+	}
+	expected := []struct {
+		line   int
+		column string
+	}{{3, "start"}, {4, "end"}, {5, "end"}}
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %v", len(expected), diags)
+	}
+	for i, e := range expected {
+		if diags[i].Line != e.line || diags[i].Column != e.column || diags[i].Severity != SeverityError {
+			t.Errorf("diagnostic %d: expected an error at line %d, column %s, got %v", i, e.line, e.column, diags[i])
+		}
+	}
+
+	_, _, err = ParseSlotsWithDiagnostics(csvData, "UTC", true)
+	if err == nil || !strings.Contains(err.Error(), "3 rows skipped, first at slots:3: start") {
+		t.Errorf("expected a strict mode error naming the first skipped row, got %v", err)
+	}
+}
@@ -0,0 +1,693 @@
+package scheduler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sheet is one worksheet of a spreadsheet as cell text. Rows[i] is row i+1 of the sheet, so
+// diagnostics for a sheet give its row numbers as lines.
+type Sheet struct {
+	Name string
+	Rows [][]string
+}
+
+// WriteCSV writes the sheet's rows as CSV, one line per row, so that line numbers in
+// diagnostics for the CSV match the sheet's row numbers.
+func (s *Sheet) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	for _, row := range s.Rows {
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ParseRegistrationsSheet parses registrations from a worksheet, with the same columns,
+// column mapping and diagnostics as ParseRegistrationsWithDiagnostics. Blank rows are skipped.
+func ParseRegistrationsSheet(sheet *Sheet, columnMapping *ColumnMapping, strict bool) (map[CourseID]*Course, []Registration, []Diagnostic, error) {
+	return parseRegistrations(&sheetRecords{rows: sheet.Rows}, columnMapping, strict)
+}
+
+// ParseHallsSheet parses halls from a worksheet, with the same columns, column mapping and
+// diagnostics as ParseHallsWithDiagnostics. Blank rows are skipped.
+func ParseHallsSheet(sheet *Sheet, columnMapping *ColumnMapping, strict bool) ([]*Hall, []Diagnostic, error) {
+	return parseHalls(&sheetRecords{rows: sheet.Rows}, columnMapping, strict)
+}
+
+// sheetRecords yields the non-blank rows of a sheet.
+type sheetRecords struct {
+	rows [][]string
+	next int
+}
+
+func (r *sheetRecords) Read() ([]string, error) {
+	for r.next < len(r.rows) {
+		row := r.rows[r.next]
+		r.next++
+		if !blankRow(row) {
+			return row, nil
+		}
+	}
+	return nil, io.EOF
+}
+
+func (r *sheetRecords) Line() int {
+	return r.next
+}
+
+func blankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// sheetMatches reports whether a sheet is the one asked for. Sheet names are compared without
+// case, as spreadsheet applications do; an empty name selects the first sheet.
+func sheetMatches(name, want string) bool {
+	return want == "" || strings.EqualFold(name, strings.TrimSpace(want))
+}
+
+func sheetNotFound(want string, names []string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("the workbook has no sheets")
+	}
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = strconv.Quote(name)
+	}
+	return fmt.Errorf("sheet %q not found; the workbook has %s", want, strings.Join(quoted, ", "))
+}
+
+// formatNumber writes a numeric cell the way it would appear in a CSV export: whole numbers
+// without a decimal point or exponent, so that numeric IDs and capacities read back unchanged.
+func formatNumber(v string) string {
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return v
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// --- XLSX ---
+
+const xlsxRelationships = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+
+// xlsxText is rich or plain text, in a shared string or an inline string cell.
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var sb strings.Builder
+	for _, r := range t.Runs {
+		sb.WriteString(r.T)
+	}
+	return sb.String()
+}
+
+// ReadXLSX reads a worksheet of an Excel workbook. sheetName selects the sheet, ignoring case;
+// if it is empty the first sheet is read. Cells hold their stored values: whole numbers are
+// written without a decimal point, booleans as true or false, and formulas give their last
+// calculated value.
+func ReadXLSX(data []byte, sheetName string) (*Sheet, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not an XLSX file: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := readZipXML(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := readZipXML(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	target := ""
+	sheet := &Sheet{}
+	for _, s := range workbook.Sheets {
+		names = append(names, s.Name)
+		if target != "" || !sheetMatches(s.Name, sheetName) {
+			continue
+		}
+		for _, r := range rels.Relationships {
+			if r.ID == s.RID {
+				target = r.Target
+			}
+		}
+		if target == "" {
+			return nil, fmt.Errorf("sheet %q has no worksheet part", s.Name)
+		}
+		sheet.Name = s.Name
+	}
+	if target == "" {
+		return nil, sheetNotFound(sheetName, names)
+	}
+	if strings.HasPrefix(target, "/") {
+		target = strings.TrimPrefix(target, "/")
+	} else {
+		target = path.Join("xl", target)
+	}
+
+	var shared struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := readZipXML(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var worksheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				R      string   `xml:"r,attr"`
+				T      string   `xml:"t,attr"`
+				V      string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := readZipXML(files, target, &worksheet); err != nil {
+		return nil, err
+	}
+
+	for _, row := range worksheet.Rows {
+		rowNum := row.R
+		if rowNum == 0 {
+			rowNum = len(sheet.Rows) + 1
+		}
+		if rowNum < len(sheet.Rows)+1 {
+			return nil, fmt.Errorf("sheet %q: row %d is out of order", sheet.Name, rowNum)
+		}
+		for len(sheet.Rows) < rowNum-1 {
+			sheet.Rows = append(sheet.Rows, nil)
+		}
+
+		var cells []string
+		for _, c := range row.Cells {
+			col := len(cells)
+			if c.R != "" {
+				if col, err = xlsxColumn(c.R); err != nil {
+					return nil, fmt.Errorf("sheet %q: %w", sheet.Name, err)
+				}
+			}
+
+			var value string
+			switch c.T {
+			case "s":
+				i, err := strconv.Atoi(strings.TrimSpace(c.V))
+				if err != nil || i < 0 || i >= len(shared.Items) {
+					return nil, fmt.Errorf("sheet %q: cell %s refers to missing shared string %q", sheet.Name, c.R, c.V)
+				}
+				value = shared.Items[i].String()
+			case "inlineStr":
+				value = c.Inline.String()
+			case "b":
+				value = strconv.FormatBool(strings.TrimSpace(c.V) == "1")
+			case "str", "e", "d":
+				value = c.V
+			default:
+				value = formatNumber(c.V)
+			}
+
+			for len(cells) < col {
+				cells = append(cells, "")
+			}
+			if col < len(cells) {
+				cells[col] = value
+			} else {
+				cells = append(cells, value)
+			}
+		}
+		sheet.Rows = append(sheet.Rows, cells)
+	}
+	return sheet, nil
+}
+
+// xlsxColumn returns the 0-based column of a cell reference such as "B7".
+func xlsxColumn(ref string) (int, error) {
+	col := 0
+	for _, ch := range ref {
+		switch {
+		case ch >= 'A' && ch <= 'Z':
+			col = col*26 + int(ch-'A') + 1
+		case ch >= 'a' && ch <= 'z':
+			col = col*26 + int(ch-'a') + 1
+		default:
+			if col == 0 {
+				return 0, fmt.Errorf("invalid cell reference %q", ref)
+			}
+			return col - 1, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid cell reference %q", ref)
+}
+
+// xlsxColumnName returns the letters of a 0-based column, e.g. "A" or "AB".
+func xlsxColumnName(col int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name
+}
+
+func readZipXML(files map[string]*zip.File, name string, v any) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeXLSX writes the sheets as an Excel workbook. Cells holding a whole number are stored as
+// numbers, everything else as text.
+func writeXLSX(w io.Writer, sheets []*Sheet) error {
+	zw := zip.NewWriter(w)
+	write := func(name string, parts ...string) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, xml.Header); err != nil {
+			return err
+		}
+		for _, part := range parts {
+			if _, err := io.WriteString(f, part); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var types, workbook, rels strings.Builder
+	types.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	workbook.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="` + xlsxRelationships + `"><sheets>`)
+	rels.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i, sheet := range sheets {
+		n := strconv.Itoa(i + 1)
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%s.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%s" r:id="rId%s"/>`, xmlEscape(sheet.Name), n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%s" Type="%s/worksheet" Target="worksheets/sheet%s.xml"/>`, n, xlsxRelationships, n)
+	}
+	types.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	rels.WriteString(`</Relationships>`)
+
+	if err := write("[Content_Types].xml", types.String()); err != nil {
+		return err
+	}
+	if err := write("_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`+
+		`<Relationship Id="rId1" Type="`+xlsxRelationships+`/officeDocument" Target="xl/workbook.xml"/></Relationships>`); err != nil {
+		return err
+	}
+	if err := write("xl/workbook.xml", workbook.String()); err != nil {
+		return err
+	}
+	if err := write("xl/_rels/workbook.xml.rels", rels.String()); err != nil {
+		return err
+	}
+
+	for i, sheet := range sheets {
+		var sb strings.Builder
+		sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+		for r, row := range sheet.Rows {
+			fmt.Fprintf(&sb, `<row r="%d">`, r+1)
+			for c, cell := range row {
+				if cell == "" {
+					continue
+				}
+				ref := xlsxColumnName(c) + strconv.Itoa(r+1)
+				if n, err := strconv.Atoi(cell); err == nil && strconv.Itoa(n) == cell {
+					fmt.Fprintf(&sb, `<c r="%s"><v>%s</v></c>`, ref, cell)
+					continue
+				}
+				fmt.Fprintf(&sb, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEscape(cell))
+			}
+			sb.WriteString(`</row>`)
+		}
+		sb.WriteString(`</sheetData></worksheet>`)
+		if err := write(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sb.String()); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func xmlEscape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// SerializeAssignmentsXLSX writes the schedule as an Excel workbook with one sheet per exam day,
+// named like "2025-01-06 Mon" and holding the columns of SerializeAssignments, and a "Halls"
+// sheet listing every hall's exams in time order. slots give each assignment its day.
+func SerializeAssignmentsXLSX(assignments []*Assignment, slots []*Slot) ([]byte, error) {
+	slotMap := make(map[SlotID]*Slot, len(slots))
+	for _, s := range slots {
+		slotMap[s.ID] = s
+	}
+
+	sorted := append([]*Assignment(nil), assignments...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].SlotDateTime != sorted[j].SlotDateTime {
+			return sorted[i].SlotDateTime < sorted[j].SlotDateTime
+		}
+		return sorted[i].CourseID < sorted[j].CourseID
+	})
+
+	header := []string{"course_id", "slot_id", "slot_datetime", "halls", "enrolled_count", "notes"}
+	var sheets []*Sheet
+	byDay := make(map[string]*Sheet)
+	type hallExam struct {
+		hall string
+		a    *Assignment
+	}
+	var hallExams []hallExam
+	for _, a := range sorted {
+		day := "Other"
+		if slot, ok := slotMap[a.SlotID]; ok {
+			day = slot.Start.Format("2006-01-02 Mon")
+		} else if t, err := time.Parse(time.RFC3339, a.SlotDateTime); err == nil {
+			day = t.Format("2006-01-02 Mon")
+		}
+		sheet, ok := byDay[day]
+		if !ok {
+			sheet = &Sheet{Name: day, Rows: [][]string{header}}
+			byDay[day] = sheet
+			sheets = append(sheets, sheet)
+		}
+		sheet.Rows = append(sheet.Rows, []string{
+			string(a.CourseID),
+			string(a.SlotID),
+			a.SlotDateTime,
+			a.Halls,
+			strconv.Itoa(a.EnrolledCount),
+			a.Notes,
+		})
+
+		for _, hallID := range strings.Split(a.Halls, ";") {
+			if hallID != "" {
+				hallExams = append(hallExams, hallExam{hall: hallID, a: a})
+			}
+		}
+	}
+	sort.SliceStable(sheets, func(i, j int) bool { return sheets[i].Name < sheets[j].Name })
+
+	sort.SliceStable(hallExams, func(i, j int) bool { return hallExams[i].hall < hallExams[j].hall })
+	halls := &Sheet{Name: "Halls", Rows: [][]string{{"hall", "slot_id", "slot_datetime", "course_id"}}}
+	for _, he := range hallExams {
+		halls.Rows = append(halls.Rows, []string{he.hall, string(he.a.SlotID), he.a.SlotDateTime, string(he.a.CourseID)})
+	}
+	sheets = append(sheets, halls)
+
+	var buf bytes.Buffer
+	if err := writeXLSX(&buf, sheets); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// --- ODS ---
+
+const (
+	odsOffice = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	odsTable  = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	odsText   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+)
+
+// ReadODS reads a sheet of an OpenDocument spreadsheet. sheetName selects the sheet, ignoring
+// case; if it is empty the first sheet is read. Numbers are written as by ReadXLSX, and text
+// cells with several paragraphs are joined with newlines.
+func ReadODS(data []byte, sheetName string) (*Sheet, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not an ODS file: %w", err)
+	}
+	var content *zip.File
+	for _, f := range zr.File {
+		if f.Name == "content.xml" {
+			content = f
+		}
+	}
+	if content == nil {
+		return nil, fmt.Errorf("missing content.xml")
+	}
+	rc, err := content.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	d := xml.NewDecoder(rc)
+	var names []string
+	var sheet *Sheet
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid content.xml: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Space != odsTable || start.Name.Local != "table" {
+			continue
+		}
+
+		name := odsAttr(start, odsTable, "name")
+		names = append(names, name)
+		if sheet != nil || !sheetMatches(name, sheetName) {
+			if err := d.Skip(); err != nil {
+				return nil, fmt.Errorf("invalid content.xml: %w", err)
+			}
+			continue
+		}
+		rows, err := readODSTable(d)
+		if err != nil {
+			return nil, fmt.Errorf("invalid content.xml: %w", err)
+		}
+		sheet = &Sheet{Name: name, Rows: rows}
+	}
+	if sheet == nil {
+		return nil, sheetNotFound(sheetName, names)
+	}
+	return sheet, nil
+}
+
+// readODSTable reads the rows of a table up to its end. Repeated blank rows, which pad a sheet
+// to the application's size, are only kept when a later row has data.
+func readODSTable(d *xml.Decoder) ([][]string, error) {
+	var rows [][]string
+	blank := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space != odsTable || t.Name.Local != "table-row" {
+				continue // Header rows and row groups hold rows, too
+			}
+			row, err := readODSRow(d)
+			if err != nil {
+				return nil, err
+			}
+			repeat := odsRepeat(t, "number-rows-repeated")
+			if blankRow(row) {
+				blank += repeat
+				continue
+			}
+			for ; blank > 0; blank-- {
+				rows = append(rows, nil)
+			}
+			for i := 0; i < repeat; i++ {
+				rows = append(rows, row)
+			}
+		case xml.EndElement:
+			if t.Name.Space == odsTable && t.Name.Local == "table" {
+				return rows, nil
+			}
+		}
+	}
+}
+
+// readODSRow reads the cells of a row up to its end, dropping trailing blank cells.
+func readODSRow(d *xml.Decoder) ([]string, error) {
+	var cells []string
+	blank := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space != odsTable || (t.Name.Local != "table-cell" && t.Name.Local != "covered-table-cell") {
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			value, err := readODSCell(d, t)
+			if err != nil {
+				return nil, err
+			}
+			repeat := odsRepeat(t, "number-columns-repeated")
+			if value == "" {
+				blank += repeat
+				continue
+			}
+			for ; blank > 0; blank-- {
+				cells = append(cells, "")
+			}
+			for i := 0; i < repeat; i++ {
+				cells = append(cells, value)
+			}
+		case xml.EndElement:
+			return cells, nil
+		}
+	}
+}
+
+// readODSCell reads a cell's value up to its end.
+func readODSCell(d *xml.Decoder, start xml.StartElement) (string, error) {
+	var value string
+	typed := true
+	switch odsAttr(start, odsOffice, "value-type") {
+	case "float", "percentage", "currency":
+		value = formatNumber(odsAttr(start, odsOffice, "value"))
+	case "boolean":
+		value = odsAttr(start, odsOffice, "boolean-value")
+	case "date":
+		value = odsAttr(start, odsOffice, "date-value")
+	case "time":
+		value = odsAttr(start, odsOffice, "time-value")
+	default:
+		typed = false
+	}
+
+	var text strings.Builder
+	paragraphs, depth := 0, 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space == odsOffice && t.Name.Local == "annotation" {
+				if err := d.Skip(); err != nil {
+					return "", err
+				}
+				continue
+			}
+			if t.Name.Space == odsText {
+				switch t.Name.Local {
+				case "p":
+					if paragraphs > 0 {
+						text.WriteByte('\n')
+					}
+					paragraphs++
+				case "s":
+					n, err := strconv.Atoi(odsAttr(t, odsText, "c"))
+					if err != nil || n < 1 {
+						n = 1
+					}
+					text.WriteString(strings.Repeat(" ", n))
+				case "tab":
+					text.WriteByte('\t')
+				case "line-break":
+					text.WriteByte('\n')
+				}
+			}
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				if typed {
+					return value, nil
+				}
+				return text.String(), nil
+			}
+			depth--
+		case xml.CharData:
+			if depth > 0 {
+				text.Write(t)
+			}
+		}
+	}
+}
+
+func odsAttr(start xml.StartElement, space, local string) string {
+	for _, a := range start.Attr {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// odsRepeat returns how many times a row or cell is repeated.
+func odsRepeat(start xml.StartElement, attr string) int {
+	n, err := strconv.Atoi(odsAttr(start, odsTable, attr))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
@@ -0,0 +1,195 @@
+package scheduler
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// zipFiles builds a zip archive from file names and contents.
+func zipFiles(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadXLSX(t *testing.T) {
+	data := zipFiles(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Notes" sheetId="1" r:id="rId1"/><sheet name="Rooms" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="/xl/worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":     `<sst><si><t>hall</t></si><si><t>capacity</t></si><si><r><t>Main </t></r><r><t>Hall</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData/></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="inlineStr"><is><t>accommodation</t></is></c></row>
+<row r="3"><c r="A3" t="s"><v>2</v></c><c r="B3"><v>1.2E2</v></c><c r="D3" t="b"><v>1</v></c></row>
+</sheetData></worksheet>`,
+	})
+
+	sheet, err := ReadXLSX(data, "rooms")
+	if err != nil {
+		t.Fatalf("ReadXLSX failed: %v", err)
+	}
+	expected := [][]string{
+		{"hall", "capacity", "", "accommodation"},
+		nil,
+		{"Main Hall", "120", "", "true"},
+	}
+	if sheet.Name != "Rooms" || !reflect.DeepEqual(sheet.Rows, expected) {
+		t.Errorf("unexpected sheet %q: %q", sheet.Name, sheet.Rows)
+	}
+
+	halls, diags, err := ParseHallsSheet(sheet, nil, true)
+	if err != nil || len(diags) != 0 {
+		t.Fatalf("ParseHallsSheet failed: %v %v", err, diags)
+	}
+	if len(halls) != 1 || halls[0].ID != "Main Hall" || halls[0].Capacity != 120 || !halls[0].Accommodation {
+		t.Errorf("unexpected halls: %+v", halls[0])
+	}
+
+	if _, err := ReadXLSX(data, "Halls"); err == nil || !strings.Contains(err.Error(), `"Notes", "Rooms"`) {
+		t.Errorf("expected an error listing the sheets, got %v", err)
+	}
+	if _, err := ReadXLSX([]byte("student_id,course_id\n"), ""); err == nil {
+		t.Error("expected an error for data that is not a workbook")
+	}
+}
+
+func TestReadODS(t *testing.T) {
+	data := zipFiles(t, map[string]string{
+		"content.xml": `<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:spreadsheet>
+<table:table table:name="Registrations">
+<table:table-column table:number-columns-repeated="3"/>
+<table:table-header-rows><table:table-row>
+<table:table-cell office:value-type="string"><text:p>student_id</text:p></table:table-cell>
+<table:table-cell table:number-columns-repeated="2"/>
+<table:table-cell office:value-type="string"><text:p>course_id</text:p></table:table-cell>
+</table:table-row></table:table-header-rows>
+<table:table-row>
+<table:table-cell office:value-type="float" office:value="20231234"><text:p>20,231,234</text:p></table:table-cell>
+<table:table-cell table:number-columns-repeated="2"/>
+<table:table-cell office:value-type="string"><text:p>CS<text:s/>101</text:p><office:annotation><text:p>note</text:p></office:annotation></table:table-cell>
+<table:table-cell table:number-columns-repeated="1020"/>
+</table:table-row>
+<table:table-row table:number-rows-repeated="2"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+<table:table-row>
+<table:table-cell office:value-type="string"><text:p>s2</text:p></table:table-cell>
+<table:table-cell table:number-columns-repeated="2"/>
+<table:table-cell office:value-type="string"><text:p>MA101</text:p></table:table-cell>
+</table:table-row>
+<table:table-row table:number-rows-repeated="1048570"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+</table:table>
+<table:table table:name="Sheet2"/>
+</office:spreadsheet></office:body></office:document-content>`,
+	})
+
+	sheet, err := ReadODS(data, "")
+	if err != nil {
+		t.Fatalf("ReadODS failed: %v", err)
+	}
+	expected := [][]string{
+		{"student_id", "", "", "course_id"},
+		{"20231234", "", "", "CS 101"},
+		nil,
+		nil,
+		{"s2", "", "", "MA101"},
+	}
+	if sheet.Name != "Registrations" || !reflect.DeepEqual(sheet.Rows, expected) {
+		t.Errorf("unexpected sheet %q: %q", sheet.Name, sheet.Rows)
+	}
+
+	courses, regs, diags, err := ParseRegistrationsSheet(sheet, nil, true)
+	if err != nil || len(diags) != 0 {
+		t.Fatalf("ParseRegistrationsSheet failed: %v %v", err, diags)
+	}
+	if len(regs) != 2 || len(courses["CS 101"].Enrollments) != 1 {
+		t.Errorf("unexpected registrations: %v", regs)
+	}
+
+	if _, err := ReadODS(data, "Sheet3"); err == nil || !strings.Contains(err.Error(), `"Registrations", "Sheet2"`) {
+		t.Errorf("expected an error listing the sheets, got %v", err)
+	}
+}
+
+func TestParseRegistrationsSheet_Diagnostics(t *testing.T) {
+	sheet := &Sheet{Rows: [][]string{
+		{"student_id", "course_id"},
+		{"s1", "CS101"},
+		nil,
+		{"s2", ""},
+	}}
+	_, _, diags, err := ParseRegistrationsSheet(sheet, nil, false)
+	if err != nil {
+		t.Fatalf("ParseRegistrationsSheet failed: %v", err)
+	}
+	if len(diags) != 1 || diags[0].String() != "registrations:4: course_id: error: empty course ID" {
+		t.Errorf("expected the sheet row in the diagnostic, got %v", diags)
+	}
+
+	var sb strings.Builder
+	if err := sheet.WriteCSV(&sb); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	_, _, csvDiags, _ := ParseRegistrationsWithDiagnostics(sb.String(), nil, false)
+	if !reflect.DeepEqual(csvDiags, diags) {
+		t.Errorf("expected the CSV to keep the row numbers, got %v", csvDiags)
+	}
+}
+
+func TestSerializeAssignmentsXLSX(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-07", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	assignments := []*Assignment{
+		{CourseID: "MA101", SlotID: slots[2].ID, SlotDateTime: "2025-01-07T09:00:00Z", Halls: "H1", EnrolledCount: 30},
+		{CourseID: "CS101", SlotID: slots[0].ID, SlotDateTime: "2025-01-06T09:00:00Z", Halls: "H1;H2", EnrolledCount: 120, Notes: "shared exam with CS101X"},
+		{CourseID: "PH101", SlotID: slots[1].ID, SlotDateTime: "2025-01-06T14:00:00Z", Halls: "H2", EnrolledCount: 40},
+	}
+
+	data, err := SerializeAssignmentsXLSX(assignments, slots)
+	if err != nil {
+		t.Fatalf("SerializeAssignmentsXLSX failed: %v", err)
+	}
+
+	monday, err := ReadXLSX(data, "")
+	if err != nil {
+		t.Fatalf("ReadXLSX failed: %v", err)
+	}
+	expected := [][]string{
+		{"course_id", "slot_id", "slot_datetime", "halls", "enrolled_count", "notes"},
+		{"CS101", string(slots[0].ID), "2025-01-06T09:00:00Z", "H1;H2", "120", "shared exam with CS101X"},
+		{"PH101", string(slots[1].ID), "2025-01-06T14:00:00Z", "H2", "40"},
+	}
+	if monday.Name != "2025-01-06 Mon" || !reflect.DeepEqual(monday.Rows, expected) {
+		t.Errorf("unexpected first sheet %q: %q", monday.Name, monday.Rows)
+	}
+
+	halls, err := ReadXLSX(data, "Halls")
+	if err != nil {
+		t.Fatalf("ReadXLSX failed: %v", err)
+	}
+	var got []string
+	for _, row := range halls.Rows[1:] {
+		got = append(got, row[0]+" "+row[3])
+	}
+	if want := []string{"H1 CS101", "H1 MA101", "H2 CS101", "H2 PH101"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected hall rows %v, got %v", want, got)
+	}
+
+	if _, err := ReadXLSX(data, "2025-01-07 Tue"); err != nil {
+		t.Errorf("expected a sheet for the second day: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StudentConstraint holds a student's approved absences and exam accommodations.
+type StudentConstraint struct {
+	StudentID    StudentID
+	Unavailable  []SlotPattern // Slots the student cannot sit any exam in
+	ExtraTime    bool          // Student has extra time and sits in a designated room
+	SeparateRoom bool          // Student sits in a designated room
+}
+
+// NeedsDesignatedRoom reports whether the student must be seated in an accommodation hall.
+func (sc *StudentConstraint) NeedsDesignatedRoom() bool {
+	return sc.ExtraTime || sc.SeparateRoom
+}
+
+// ParseStudentConstraints parses the student constraints CSV data.
+// Required column is student_id; unavailable (semicolon-separated slot patterns), extra_time
+// and separate_room are optional. A student may appear on several rows, which are merged.
+// Rows that cannot be used are skipped; see ParseStudentConstraintsWithDiagnostics to find out which.
+func ParseStudentConstraints(csvData string) (map[StudentID]*StudentConstraint, error) {
+	constraints, _, err := ParseStudentConstraintsWithDiagnostics(csvData, false)
+	return constraints, err
+}
+
+// ParseStudentConstraintsWithDiagnostics parses the student constraints CSV data like
+// ParseStudentConstraints and also reports every skipped row. In strict mode any skipped row is an error.
+func ParseStudentConstraintsWithDiagnostics(csvData string, strict bool) (map[StudentID]*StudentConstraint, []Diagnostic, error) {
+	constraints := make(map[StudentID]*StudentConstraint)
+	if csvData == "" {
+		return constraints, nil, nil
+	}
+
+	table, err := newCSVTable("student constraints", csvData, "student_id")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for table.next() {
+		studentID := StudentID(table.field("student_id"))
+		if studentID == "" {
+			table.skip("student_id", "empty student ID")
+			continue
+		}
+
+		unavailable, err := ParseSlotPatterns(table.field("unavailable"))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid unavailable slots for student %s: %w", studentID, err)
+		}
+
+		sc, ok := constraints[studentID]
+		if !ok {
+			sc = &StudentConstraint{StudentID: studentID}
+			constraints[studentID] = sc
+		}
+		sc.Unavailable = append(sc.Unavailable, unavailable...)
+		sc.ExtraTime = sc.ExtraTime || parseFlag(table.field("extra_time"))
+		sc.SeparateRoom = sc.SeparateRoom || parseFlag(table.field("separate_room"))
+	}
+
+	diagnostics, err := table.result(strict)
+	if err != nil {
+		return nil, diagnostics, err
+	}
+	return constraints, diagnostics, nil
+}
+
+// parseFlag interprets a CSV cell as a boolean flag.
+func parseFlag(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "true", "yes", "y", "x":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"testing"
+)
+
+func TestParseStudentConstraints(t *testing.T) {
+	constraints, err := ParseStudentConstraints(`student_id,unavailable,extra_time,separate_room
+s1,2025-01-06;2025-01-07T09:00Z#1,,
+s2,,yes,
+s1,,,1
+`)
+	if err != nil {
+		t.Fatalf("ParseStudentConstraints failed: %v", err)
+	}
+	if len(constraints) != 2 {
+		t.Fatalf("expected 2 students, got %d", len(constraints))
+	}
+	if len(constraints["s1"].Unavailable) != 2 || !constraints["s1"].SeparateRoom {
+		t.Errorf("rows for s1 were not merged: %+v", constraints["s1"])
+	}
+	if !constraints["s2"].ExtraTime || !constraints["s2"].NeedsDesignatedRoom() {
+		t.Errorf("expected s2 to need a designated room: %+v", constraints["s2"])
+	}
+}
+
+func TestRestrictAllowedSlots(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-07", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	courses := map[CourseID]*Course{
+		"c1": {ID: "c1", Enrollments: []StudentID{"s1", "s2"}},
+		"c2": {ID: "c2", Enrollments: []StudentID{"s3"}},
+	}
+	students, _ := ParseStudentConstraints(`student_id,unavailable
+s1,2025-01-06
+`)
+	constraints := &Constraints{Students: students}
+
+	allowed, err := RestrictAllowedSlots(make(map[CourseID]map[SlotID]bool), courses, slots, constraints)
+	if err != nil {
+		t.Fatalf("RestrictAllowedSlots failed: %v", err)
+	}
+	if len(allowed["c1"]) != 2 || !allowed["c1"][slots[2].ID] || !allowed["c1"][slots[3].ID] {
+		t.Errorf("expected c1 to be restricted to the second day, got %v", allowed["c1"])
+	}
+	if _, ok := allowed["c2"]; ok {
+		t.Errorf("c2 should stay unrestricted, got %v", allowed["c2"])
+	}
+
+	// Combined with an allowed set on the unavailable day, nothing is left.
+	_, err = RestrictAllowedSlots(map[CourseID]map[SlotID]bool{"c1": {slots[0].ID: true}}, courses, slots, constraints)
+	if err == nil {
+		t.Error("expected an error when no feasible slot is left")
+	}
+}
+
+func TestRunSchedulingAttempts_StudentConstraints(t *testing.T) {
+	courses, regs, _ := ParseRegistrations(`student_id,course_id
+s1,c1
+s2,c1
+s3,c2
+`, nil)
+	halls, _ := ParseHalls(`hall,capacity,accommodation
+H1,10,
+Quiet,2,yes
+`, nil)
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-07", 1, []string{"09:00"}, 180, nil, "UTC")
+	students, _ := ParseStudentConstraints(`student_id,unavailable,extra_time
+s1,2025-01-06,
+s2,,yes
+`)
+	constraints := &Constraints{Students: students}
+	graph := NewConflictGraph(courses)
+
+	result, err := RunSchedulingAttemptsWithConstraints(5, 1, courses, halls, slots, make(map[CourseID]map[SlotID]bool), graph, 0, PenaltyConfig{}, constraints)
+	if err != nil {
+		t.Fatalf("RunSchedulingAttempts failed: %v", err)
+	}
+
+	scheduleCSV, _ := SerializeAssignments(result.Assignments)
+	report, err := VerifyScheduleWithConstraints(regs, scheduleCSV, halls, slots, constraints)
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithConstraints failed: %v", err)
+	}
+	if !report.Valid {
+		t.Errorf("schedule should satisfy the student constraints, got %+v", report)
+	}
+	for _, a := range result.Assignments {
+		if a.CourseID == "c1" && a.SlotID != slots[1].ID {
+			t.Errorf("c1 should be on the second day, got %s", a.SlotID)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TravelTimes holds the travel time in minutes between pairs of campuses. A hall's campus is its Group.
+type TravelTimes map[string]map[string]int
+
+// Minutes returns the travel time between two campuses; it is 0 within a campus.
+// The second result is false if the pair is not in the matrix.
+func (tt TravelTimes) Minutes(from, to string) (int, bool) {
+	if from == to {
+		return 0, true
+	}
+	minutes, ok := tt[from][to]
+	return minutes, ok
+}
+
+// ParseTravelTimes parses the campus travel times CSV data.
+// Required columns are from, to and minutes. Travel times are symmetric, so each pair of
+// campuses needs only one row; pairs that are not listed are not checked. Rows that cannot be
+// used are skipped; see ParseTravelTimesWithDiagnostics to find out which.
+func ParseTravelTimes(csvData string) (TravelTimes, error) {
+	tt, _, err := ParseTravelTimesWithDiagnostics(csvData, false)
+	return tt, err
+}
+
+// ParseTravelTimesWithDiagnostics parses the campus travel times CSV data like ParseTravelTimes
+// and also reports every skipped row. In strict mode any skipped row is an error.
+func ParseTravelTimesWithDiagnostics(csvData string, strict bool) (TravelTimes, []Diagnostic, error) {
+	if csvData == "" {
+		return nil, nil, nil
+	}
+
+	table, err := newCSVTable("travel times", csvData, "from", "to", "minutes")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tt := make(TravelTimes)
+	set := func(from, to string, minutes int) {
+		if tt[from] == nil {
+			tt[from] = make(map[string]int)
+		}
+		tt[from][to] = minutes
+	}
+	for table.next() {
+		from, to := table.field("from"), table.field("to")
+		if from == "" || to == "" {
+			table.skip("", "row needs both campuses")
+			continue
+		}
+		minutes, err := strconv.Atoi(table.field("minutes"))
+		if err != nil || minutes < 0 {
+			return nil, nil, fmt.Errorf("invalid travel time %q from %s to %s", table.field("minutes"), from, to)
+		}
+		set(from, to, minutes)
+		set(to, from, minutes)
+	}
+
+	diagnostics, err := table.result(strict)
+	if err != nil {
+		return nil, diagnostics, err
+	}
+	return tt, diagnostics, nil
+}
+
+// campusTravel finds students with consecutive exams on the same day at campuses that are
+// further apart than the gap between the exams allows. It returns one warning per pair of
+// courses and the number of affected student exam pairs. Courses split over halls on several
+// campuses are checked against their furthest campus.
+func campusTravel(
+	assignments []*Assignment,
+	courses map[CourseID]*Course,
+	halls []*Hall,
+	slots []*Slot,
+	travel TravelTimes,
+) ([]string, int) {
+	if len(travel) == 0 {
+		return nil, 0
+	}
+
+	slotMap := make(map[SlotID]*Slot, len(slots))
+	for _, s := range slots {
+		slotMap[s.ID] = s
+	}
+	hallCampus := make(map[HallID]string, len(halls))
+	for _, h := range halls {
+		hallCampus[h.ID] = h.Group
+	}
+
+	type exam struct {
+		courseID CourseID
+		slot     *Slot
+		campuses []string
+	}
+	exams := make(map[CourseID]*exam, len(assignments))
+	for _, a := range assignments {
+		slot, ok := slotMap[a.SlotID]
+		if !ok || a.Halls == "" {
+			continue
+		}
+		e := &exam{courseID: a.CourseID, slot: slot}
+		for _, hallID := range strings.Split(a.Halls, ";") {
+			if campus := hallCampus[HallID(hallID)]; campus != "" && !containsString(e.campuses, campus) {
+				e.campuses = append(e.campuses, campus)
+			}
+		}
+		if len(e.campuses) > 0 {
+			exams[a.CourseID] = e
+		}
+	}
+
+	type coursePair struct{ first, second CourseID }
+	type issue struct {
+		students    int
+		travel, gap int
+		from, to    string
+	}
+	issues := make(map[coursePair]*issue)
+
+	studentExams := make(map[StudentID][]*exam)
+	for courseID, course := range courses {
+		e, ok := exams[courseID]
+		if !ok {
+			continue
+		}
+		for _, studentID := range course.Enrollments {
+			studentExams[studentID] = append(studentExams[studentID], e)
+		}
+	}
+
+	count := 0
+	for _, list := range studentExams {
+		sort.Slice(list, func(i, j int) bool {
+			if !list[i].slot.Start.Equal(list[j].slot.Start) {
+				return list[i].slot.Start.Before(list[j].slot.Start)
+			}
+			return list[i].courseID < list[j].courseID
+		})
+		for i := 1; i < len(list); i++ {
+			prev, next := list[i-1], list[i]
+			if prev.slot.DayIndex != next.slot.DayIndex {
+				continue
+			}
+			gap := int(next.slot.Start.Sub(prev.slot.End) / time.Minute)
+			if gap < 0 {
+				continue // Overlapping exams are a clash, reported elsewhere
+			}
+			worst, from, to := -1, "", ""
+			for _, a := range prev.campuses {
+				for _, b := range next.campuses {
+					if minutes, ok := travel.Minutes(a, b); ok && minutes > worst {
+						worst, from, to = minutes, a, b
+					}
+				}
+			}
+			if worst <= gap {
+				continue
+			}
+			count++
+			key := coursePair{prev.courseID, next.courseID}
+			if issues[key] == nil {
+				issues[key] = &issue{travel: worst, gap: gap, from: from, to: to}
+			}
+			issues[key].students++
+		}
+	}
+
+	keys := make([]coursePair, 0, len(issues))
+	for k := range issues {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].first != keys[j].first {
+			return keys[i].first < keys[j].first
+		}
+		return keys[i].second < keys[j].second
+	})
+	warnings := make([]string, 0, len(keys))
+	for _, k := range keys {
+		is := issues[k]
+		warnings = append(warnings, fmt.Sprintf("%d students go from course %s at %s to course %s at %s with %d minutes between exams but %d minutes of travel",
+			is.students, k.first, is.from, k.second, is.to, is.gap, is.travel))
+	}
+	return warnings, count
+}
@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTravelTimes(t *testing.T) {
+	travel, err := ParseTravelTimes(`from,to,minutes
+North,South,45
+North,East,20
+`)
+	if err != nil {
+		t.Fatalf("ParseTravelTimes failed: %v", err)
+	}
+	if m, ok := travel.Minutes("South", "North"); !ok || m != 45 {
+		t.Errorf("expected 45 minutes from South to North, got %d (%v)", m, ok)
+	}
+	if m, ok := travel.Minutes("East", "East"); !ok || m != 0 {
+		t.Errorf("expected no travel within a campus, got %d (%v)", m, ok)
+	}
+	if _, ok := travel.Minutes("South", "East"); ok {
+		t.Error("expected South to East to be unknown")
+	}
+
+	if _, err := ParseTravelTimes("from,to,minutes\nNorth,South,soon\n"); err == nil {
+		t.Error("expected an error for an invalid travel time")
+	}
+}
+
+func TestCampusTravel(t *testing.T) {
+	// Two slots with a 30 minute break, then a slot the next day.
+	slots, err := ParseSlots(`id,start,end
+AM,2025-01-06 09:00,2025-01-06 12:00
+PM,2025-01-06 12:30,2025-01-06 15:30
+NEXT,2025-01-07 09:00,2025-01-07 12:00
+`, "UTC")
+	if err != nil {
+		t.Fatalf("ParseSlots failed: %v", err)
+	}
+	halls := []*Hall{
+		{ID: "N1", Capacity: 100, Group: "North"},
+		{ID: "N2", Capacity: 100, Group: "North"},
+		{ID: "S1", Capacity: 100, Group: "South"},
+		{ID: "E1", Capacity: 100, Group: "East"},
+	}
+	courses := map[CourseID]*Course{
+		"c1": {ID: "c1", Enrollments: []StudentID{"s1", "s2", "s3"}},
+		"c2": {ID: "c2", Enrollments: []StudentID{"s1", "s2"}},
+		"c3": {ID: "c3", Enrollments: []StudentID{"s3"}},
+		"c4": {ID: "c4", Enrollments: []StudentID{"s1"}},
+	}
+	assignments := []*Assignment{
+		{CourseID: "c1", SlotID: "AM", Halls: "N1"},
+		{CourseID: "c2", SlotID: "PM", Halls: "S1"}, // 45 minutes away with a 30 minute break
+		{CourseID: "c3", SlotID: "PM", Halls: "E1"}, // 20 minutes away
+		{CourseID: "c4", SlotID: "NEXT", Halls: "S1"},
+	}
+	travel, _ := ParseTravelTimes(`from,to,minutes
+North,South,45
+North,East,20
+`)
+
+	warnings, count := campusTravel(assignments, courses, halls, slots, travel)
+	if count != 2 {
+		t.Errorf("expected 2 affected students, got %d", count)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "2 students go from course c1 at North to course c2 at South") {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	if warnings, count := campusTravel(assignments, courses, halls, slots, nil); warnings != nil || count != 0 {
+		t.Errorf("expected no travel issues without travel times, got %v", warnings)
+	}
+}
@@ -3,7 +3,10 @@ package scheduler
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
+	"sort"
 	"strings"
+	"time"
 )
 
 // ValidationReport contains the results of a schedule verification.
@@ -14,6 +17,10 @@ type ValidationReport struct {
 	CapacityWarnings []string   `json:"capacityWarnings"`
 	Errors           []string   `json:"errors"`
 	StudentClashes   []string   `json:"studentClashes"`
+	// ConstraintViolations lists broken hard constraints beyond student clashes
+	ConstraintViolations []string `json:"constraintViolations"`
+	// TravelWarnings lists students who cannot reach the campus of their next exam in time
+	TravelWarnings []string `json:"travelWarnings"`
 }
 
 // VerifySchedule checks a generated schedule for correctness against the original registrations.
@@ -28,6 +35,180 @@ func VerifySchedule(registrations []Registration, scheduleCSV string, halls []*H
 		return report, err
 	}
 
+	verifyAssignments(report, registrations, assignments, halls)
+	return report, nil
+}
+
+// VerifyScheduleWithConstraints runs the checks of VerifySchedule and then checks the schedule
+// against the given constraints, which may be nil. Slots are needed to resolve date-based rules.
+// With course aliases, the rows of courses sharing an exam are checked as one exam. With a
+// sitting plan, each student of a split course must be in exactly one sitting.
+func VerifyScheduleWithConstraints(registrations []Registration, scheduleCSV string, halls []*Hall, slots []*Slot, constraints *Constraints) (*ValidationReport, error) {
+	report := &ValidationReport{Valid: true}
+
+	assignments, err := parseScheduleCSV(scheduleCSV)
+	if err != nil {
+		report.Valid = false
+		report.Errors = append(report.Errors, fmt.Sprintf("error parsing schedule CSV: %v", err))
+		return report, err
+	}
+
+	verifyWithConstraints(report, registrations, assignments, halls, slots, constraints)
+	return report, nil
+}
+
+// VerifyScheduleWithSettings runs the checks of VerifyScheduleWithConstraints and also checks the
+// schedule against the settings it was made with: every course must be in one of the slots, at the
+// slot's time, and in a slot it is allowed; a sitting of a split course is allowed the slots of
+// the course. A student's exams must start at least minGapMinutes apart; breaches are reported
+// as ConstraintViolations. Halls, allowedSlots and constraints may be nil to skip their checks.
+func VerifyScheduleWithSettings(registrations []Registration, scheduleCSV string, halls []*Hall, slots []*Slot, allowedSlots map[CourseID]map[SlotID]bool, minGapMinutes int, constraints *Constraints) (*ValidationReport, error) {
+	report := &ValidationReport{Valid: true}
+
+	assignments, err := parseScheduleCSV(scheduleCSV)
+	if err != nil {
+		report.Valid = false
+		report.Errors = append(report.Errors, fmt.Sprintf("error parsing schedule CSV: %v", err))
+		return report, err
+	}
+
+	verifyWithSettings(report, registrations, assignments, halls, slots, allowedSlots, minGapMinutes, constraints)
+	return report, nil
+}
+
+// verifyWithSettings runs the checks of VerifyScheduleWithSettings on parsed assignments.
+func verifyWithSettings(report *ValidationReport, registrations []Registration, assignments []*Assignment, halls []*Hall, slots []*Slot, allowedSlots map[CourseID]map[SlotID]bool, minGapMinutes int, constraints *Constraints) {
+	checkSlots(report, assignments, slots, allowedSlots, constraints)
+	verifyWithConstraints(report, registrations, assignments, halls, slots, constraints)
+	checkMinGap(report, registrations, assignments, slots, minGapMinutes, constraints)
+}
+
+// checkSlots checks that every assignment is in a known slot, at its time, and in an allowed slot.
+func checkSlots(report *ValidationReport, assignments []*Assignment, slots []*Slot, allowedSlots map[CourseID]map[SlotID]bool, constraints *Constraints) {
+	slotMap := make(map[SlotID]*Slot, len(slots))
+	for _, s := range slots {
+		slotMap[s.ID] = s
+	}
+	parentOf := make(map[CourseID]CourseID)
+	if constraints != nil && constraints.Sittings != nil {
+		for courseID, sittings := range constraints.Sittings.Sittings {
+			for _, sitting := range sittings {
+				parentOf[sitting] = courseID
+			}
+		}
+	}
+
+	for _, a := range assignments {
+		slot, ok := slotMap[a.SlotID]
+		if !ok {
+			report.Errors = append(report.Errors, fmt.Sprintf("course %s is in unknown slot %s", a.CourseID, a.SlotID))
+			report.Valid = false
+			continue
+		}
+		if start, err := time.Parse(time.RFC3339, a.SlotDateTime); err == nil && !start.Equal(slot.Start) {
+			report.Errors = append(report.Errors, fmt.Sprintf("course %s is at %s but slot %s starts at %s", a.CourseID, a.SlotDateTime, a.SlotID, slot.Start.Format(time.RFC3339)))
+			report.Valid = false
+		}
+
+		allowed := allowedSlots[a.CourseID]
+		if len(allowed) == 0 {
+			allowed = allowedSlots[parentOf[a.CourseID]]
+		}
+		if len(allowed) > 0 && !allowed[a.SlotID] {
+			report.ConstraintViolations = append(report.ConstraintViolations,
+				fmt.Sprintf("course %s is in slot %s, which is not one of its allowed slots", a.CourseID, a.SlotID))
+			report.Valid = false
+		}
+	}
+}
+
+// checkMinGap reports students with two exams starting less than minGapMinutes apart as
+// constraint violations. Students of a split course are checked in their own sitting.
+func checkMinGap(report *ValidationReport, registrations []Registration, assignments []*Assignment, slots []*Slot, minGapMinutes int, constraints *Constraints) {
+	if minGapMinutes <= 0 {
+		return
+	}
+	slotMap := make(map[SlotID]*Slot, len(slots))
+	for _, s := range slots {
+		slotMap[s.ID] = s
+	}
+	assignmentMap := make(map[CourseID]*Assignment, len(assignments))
+	for _, a := range assignments {
+		assignmentMap[a.CourseID] = a
+	}
+	sittingOf := make(map[CourseID]map[StudentID]CourseID)
+	if constraints != nil && constraints.Sittings != nil {
+		for courseID, sittings := range constraints.Sittings.Sittings {
+			sittingOf[courseID] = make(map[StudentID]CourseID)
+			for _, sitting := range sittings {
+				for _, studentID := range constraints.Sittings.Students[sitting] {
+					sittingOf[courseID][studentID] = sitting
+				}
+			}
+		}
+	}
+
+	type exam struct {
+		courseID CourseID
+		slot     *Slot
+	}
+	studentExams := make(map[StudentID][]exam)
+	var students []StudentID
+	for _, reg := range registrations {
+		courseID := reg.CourseID
+		if sitting, ok := sittingOf[courseID][reg.StudentID]; ok {
+			courseID = sitting
+		}
+		a, ok := assignmentMap[courseID]
+		if !ok {
+			continue
+		}
+		slot, ok := slotMap[a.SlotID]
+		if !ok {
+			continue
+		}
+		if _, seen := studentExams[reg.StudentID]; !seen {
+			students = append(students, reg.StudentID)
+		}
+		studentExams[reg.StudentID] = append(studentExams[reg.StudentID], exam{courseID, slot})
+	}
+
+	minGap := time.Duration(minGapMinutes) * time.Minute
+	for _, studentID := range students {
+		exams := studentExams[studentID]
+		sort.SliceStable(exams, func(i, j int) bool { return exams[i].slot.Start.Before(exams[j].slot.Start) })
+		for i := 1; i < len(exams); i++ {
+			prev, next := exams[i-1], exams[i]
+			if prev.slot.ID == next.slot.ID {
+				continue // A clash, reported as such
+			}
+			if gap := next.slot.Start.Sub(prev.slot.Start); gap < minGap {
+				report.ConstraintViolations = append(report.ConstraintViolations,
+					fmt.Sprintf("student %s has %s and %s starting %d minutes apart, less than the minimum gap of %d", studentID, prev.courseID, next.courseID, int(gap/time.Minute), minGapMinutes))
+				report.Valid = false
+			}
+		}
+	}
+}
+
+// verifyWithConstraints runs the checks of VerifyScheduleWithConstraints on parsed assignments.
+func verifyWithConstraints(report *ValidationReport, registrations []Registration, assignments []*Assignment, halls []*Hall, slots []*Slot, constraints *Constraints) {
+	if constraints != nil && len(constraints.Aliases) > 0 {
+		courses := coursesFromRegistrations(registrations)
+		registrations, assignments = mergeAliasedAssignments(report, registrations, assignments, constraints.Aliases)
+		constraints = constraints.withAliases(constraints.Aliases, courses)
+	}
+	if constraints != nil && constraints.Sittings != nil && len(constraints.Sittings.Sittings) > 0 {
+		registrations = assignSittings(report, registrations, constraints.Sittings)
+		constraints = constraints.withSittings(constraints.Sittings)
+	}
+
+	verifyAssignments(report, registrations, assignments, halls)
+	checkConstraints(report, registrations, assignments, halls, slots, constraints)
+}
+
+// verifyAssignments checks student clashes, hall usage and unassigned courses.
+func verifyAssignments(report *ValidationReport, registrations []Registration, assignments []*Assignment, halls []*Hall) {
 	// --- Data structures for verification ---
 	// Map student to their scheduled slots
 	studentSchedules := make(map[StudentID]map[SlotID]bool)
@@ -77,7 +258,7 @@ func VerifySchedule(registrations []Registration, scheduleCSV string, halls []*H
 			}
 			hallID := HallID(hallIDStr)
 			capacity, ok := hallCapacityMap[hallID]
-			if !ok {
+			if !ok && len(halls) > 0 {
 				warning := fmt.Sprintf("course %s assigned to unknown hall %s", assignment.CourseID, hallID)
 				report.CapacityWarnings = append(report.CapacityWarnings, warning)
 				continue
@@ -96,7 +277,8 @@ func VerifySchedule(registrations []Registration, scheduleCSV string, halls []*H
 			}
 		}
 
-		if totalCapacity < assignment.EnrolledCount {
+		// Without halls, only double-booking can be checked
+		if len(halls) > 0 && totalCapacity < assignment.EnrolledCount {
 			warning := fmt.Sprintf("course %s has insufficient capacity. Enrolled: %d, Allocated: %d in halls [%s]",
 				assignment.CourseID, assignment.EnrolledCount, totalCapacity, assignment.Halls)
 			report.CapacityWarnings = append(report.CapacityWarnings, warning)
@@ -116,15 +298,18 @@ func VerifySchedule(registrations []Registration, scheduleCSV string, halls []*H
 	if len(report.Unassigned) > 0 {
 		report.Valid = false
 	}
-
-	return report, nil
 }
 
 // parseScheduleCSV is a helper to parse the schedule CSV for verification.
 func parseScheduleCSV(csvData string) ([]*Assignment, error) {
+	return readScheduleCSV(strings.NewReader(csvData))
+}
+
+// readScheduleCSV reads a schedule CSV row by row.
+func readScheduleCSV(r io.Reader) ([]*Assignment, error) {
 	var assignments []*Assignment
 	// gocsv has issues with custom parsing needs here, so we use the standard library
-	reader := csv.NewReader(strings.NewReader(csvData))
+	reader := csv.NewReader(r)
 	reader.LazyQuotes = true
 	reader.TrimLeadingSpace = true
 
@@ -148,12 +333,14 @@ func parseScheduleCSV(csvData string) ([]*Assignment, error) {
 		}
 	}
 
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, err
-	}
-
-	for _, record := range records {
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
 		if len(record) < 6 {
 			continue // Skip malformed records
 		}
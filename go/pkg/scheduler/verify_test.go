@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -104,3 +105,236 @@ H1,1
 		t.Errorf("expected 1 capacity warning, got %d", len(report.CapacityWarnings))
 	}
 }
+
+func TestVerifyScheduleWithConstraints_StudentUnavailable(t *testing.T) {
+	_, regs, _ := ParseRegistrations(`student_id,course_id
+s1,c1
+s2,c1
+`, nil)
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-06", 1, []string{"09:00"}, 180, nil, "UTC")
+	scheduleCSV := `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+c1,2025-01-06T09:00Z#1,2025-01-06T09:00:00Z,H1,2,
+`
+	halls, _ := ParseHalls(`hall,capacity
+H1,100
+`, nil)
+	students, _ := ParseStudentConstraints(`student_id,unavailable,separate_room
+s1,2025-01-06,
+s2,,yes
+`)
+
+	report, err := VerifyScheduleWithConstraints(regs, scheduleCSV, halls, slots, &Constraints{Students: students})
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithConstraints failed: %v", err)
+	}
+	if report.Valid {
+		t.Error("schedule should be invalid")
+	}
+	// s1 is unavailable, and s2 has no designated room.
+	if len(report.ConstraintViolations) != 2 {
+		t.Errorf("expected 2 constraint violations, got %v", report.ConstraintViolations)
+	}
+}
+
+func TestVerifyScheduleWithConstraints_Relations(t *testing.T) {
+	_, regs, _ := ParseRegistrations(`student_id,course_id
+s1,PHYS101
+s2,PHYS201
+`, nil)
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-06", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	scheduleCSV := `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+PHYS201,2025-01-06T09:00Z#1,2025-01-06T09:00:00Z,H1,1,
+PHYS101,2025-01-06T14:00Z#2,2025-01-06T14:00:00Z,H1,1,
+`
+	halls, _ := ParseHalls(`hall,capacity
+H1,100
+`, nil)
+	constraints := &Constraints{Relations: []CourseRelation{{A: "PHYS101", Kind: RelationPrecedes, B: "PHYS201"}}}
+
+	report, err := VerifyScheduleWithConstraints(regs, scheduleCSV, halls, slots, constraints)
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithConstraints failed: %v", err)
+	}
+	if report.Valid || len(report.ConstraintViolations) != 1 {
+		t.Errorf("expected 1 relation violation, got %+v", report)
+	}
+}
+
+func TestVerifyScheduleWithConstraints_HallFeatures(t *testing.T) {
+	_, regs, _ := ParseRegistrations(`student_id,course_id
+s1,CS101
+`, nil)
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-06", 1, []string{"09:00"}, 180, nil, "UTC")
+	scheduleCSV := `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+CS101,2025-01-06T09:00Z#1,2025-01-06T09:00:00Z,H1,1,
+`
+	halls, _ := ParseHalls(`hall,capacity,features
+H1,100,
+Lab1,30,lab
+`, nil)
+	constraints := &Constraints{Requirements: map[CourseID][]string{"CS101": {"lab"}}}
+
+	report, err := VerifyScheduleWithConstraints(regs, scheduleCSV, halls, slots, constraints)
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithConstraints failed: %v", err)
+	}
+	if report.Valid || len(report.ConstraintViolations) != 1 {
+		t.Errorf("expected 1 hall feature violation, got %+v", report)
+	}
+}
+
+func TestVerifyScheduleWithConstraints_HallAvailability(t *testing.T) {
+	_, regs, _ := ParseRegistrations(`student_id,course_id
+s1,CS101
+s2,MA101
+`, nil)
+	slots, _ := GenerateSlots("2025-01-08", "2025-01-08", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	scheduleCSV := `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+CS101,2025-01-08T09:00Z#1,2025-01-08T09:00:00Z,Sports,1,
+MA101,2025-01-08T14:00Z#2,2025-01-08T14:00:00Z,H1,1,
+`
+	halls, _ := ParseHalls(`hall,capacity
+H1,100
+Sports,300
+`, nil)
+	windows, _ := ParseHallAvailability(`hall,slot
+Sports,Wed
+`)
+	constraints := &Constraints{HallWindows: windows}
+
+	report, err := VerifyScheduleWithConstraints(regs, scheduleCSV, halls, slots, constraints)
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithConstraints failed: %v", err)
+	}
+	if report.Valid || len(report.ConstraintViolations) != 1 {
+		t.Errorf("expected 1 hall availability violation, got %+v", report)
+	}
+}
+
+func TestVerifyScheduleWithConstraints_CampusTravel(t *testing.T) {
+	_, regs, _ := ParseRegistrations(`student_id,course_id
+s1,c1
+s1,c2
+`, nil)
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-06", 2, []string{"09:00", "12:30"}, 180, nil, "UTC")
+	scheduleCSV := `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+c1,2025-01-06T09:00Z#1,2025-01-06T09:00:00Z,N1,1,
+c2,2025-01-06T12:30Z#2,2025-01-06T12:30:00Z,S1,1,
+`
+	halls, _ := ParseHalls(`hall,capacity,group
+N1,100,North
+S1,100,South
+`, nil)
+	travel, _ := ParseTravelTimes("from,to,minutes\nNorth,South,45\n")
+
+	report, err := VerifyScheduleWithConstraints(regs, scheduleCSV, halls, slots, &Constraints{Travel: travel})
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithConstraints failed: %v", err)
+	}
+	if !report.Valid {
+		t.Errorf("travel problems should not make the schedule invalid: %+v", report)
+	}
+	if len(report.TravelWarnings) != 1 {
+		t.Errorf("expected 1 travel warning, got %v", report.TravelWarnings)
+	}
+}
+
+func TestVerifyScheduleWithSettings(t *testing.T) {
+	_, regs, _ := ParseRegistrations(`student_id,course_id
+s1,c1
+s1,c2
+s2,c3
+s3,c4
+`, nil)
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-06", 2, []string{"09:00", "11:00"}, 60, nil, "UTC")
+	scheduleCSV := `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+c1,2025-01-06T09:00Z#1,2025-01-06T09:00:00Z,H1,1,
+c2,2025-01-06T11:00Z#2,2025-01-06T11:00:00Z,H1,1,
+c3,2025-01-07T09:00Z#1,2025-01-07T09:00:00Z,H2,1,
+c4,2025-01-06T09:00Z#1,2025-01-06T10:00:00Z,H2,1,
+`
+	halls, _ := ParseHalls(`hall,capacity
+H1,10
+H2,10
+`, nil)
+	allowed := map[CourseID]map[SlotID]bool{"c2": {slots[0].ID: true}}
+
+	report, err := VerifyScheduleWithSettings(regs, scheduleCSV, halls, slots, allowed, 180, nil)
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithSettings failed: %v", err)
+	}
+	if report.Valid {
+		t.Error("schedule should be invalid")
+	}
+	// c3 is in a slot that does not exist and c4 is not at its slot's time
+	if len(report.Errors) != 2 || !strings.Contains(report.Errors[0], "unknown slot") || !strings.Contains(report.Errors[1], "starts at") {
+		t.Errorf("expected slot errors, got %v", report.Errors)
+	}
+	// c2 is outside its allowed slots and s1's exams are too close together
+	if len(report.ConstraintViolations) != 2 || !strings.Contains(report.ConstraintViolations[0], "course c2") ||
+		!strings.Contains(report.ConstraintViolations[1], "starting 120 minutes apart") {
+		t.Errorf("expected c2 to be outside its allowed slots and a min gap violation for s1, got %v", report.ConstraintViolations)
+	}
+
+	// A sitting is allowed the slots of its course
+	_, regs, _ = ParseRegistrations(`student_id,course_id
+s1,c1
+s2,c1
+`, nil)
+	scheduleCSV = `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+c1/S1,2025-01-06T09:00Z#1,2025-01-06T09:00:00Z,H1,1,
+c1/S2,2025-01-06T11:00Z#2,2025-01-06T11:00:00Z,H1,1,
+`
+	plan := &SittingPlan{
+		Sittings: map[CourseID][]CourseID{"c1": {"c1/S1", "c1/S2"}},
+		Students: map[CourseID][]StudentID{"c1/S1": {"s1"}, "c1/S2": {"s2"}},
+	}
+	allowed = map[CourseID]map[SlotID]bool{"c1": {slots[0].ID: true}}
+	report, _ = VerifyScheduleWithSettings(regs, scheduleCSV, halls, slots, allowed, 180, &Constraints{Sittings: plan})
+	// Students in different sittings do not breach the min gap
+	if len(report.ConstraintViolations) != 1 || !strings.Contains(report.ConstraintViolations[0], "c1/S2") {
+		t.Errorf("expected the second sitting to be outside the course's allowed slots, got %v", report.ConstraintViolations)
+	}
+}
+
+func TestVerifySchedule_WithoutHalls(t *testing.T) {
+	_, regs, _ := ParseRegistrations(`student_id,course_id
+s1,c1
+s2,c2
+`, nil)
+	scheduleCSV := `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+c1,slot1,2025-01-06T09:00:00Z,H1,1,
+c2,slot1,2025-01-06T09:00:00Z,H1,1,
+`
+	report, err := VerifySchedule(regs, scheduleCSV, nil)
+	if err != nil {
+		t.Fatalf("VerifySchedule failed: %v", err)
+	}
+	if len(report.CapacityWarnings) != 0 {
+		t.Errorf("expected no capacity checks without halls, got %v", report.CapacityWarnings)
+	}
+	if report.Valid || len(report.Errors) != 1 {
+		t.Errorf("expected the double-booked hall to be reported, got %v", report.Errors)
+	}
+}
+
+func TestVerifyScheduleWithSettings_MinGapInvalid(t *testing.T) {
+	_, regs, _ := ParseRegistrations("student_id,course_id\ns1,c1\ns1,c2\n", nil)
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-06", 2, []string{"09:00", "11:00"}, 60, nil, "UTC")
+	scheduleCSV := `course_id,slot_id,slot_datetime,halls,enrolled_count,notes
+c1,2025-01-06T09:00Z#1,2025-01-06T09:00:00Z,,1,
+c2,2025-01-06T11:00Z#2,2025-01-06T11:00:00Z,,1,
+`
+	report, err := VerifyScheduleWithSettings(regs, scheduleCSV, nil, slots, nil, 120, nil)
+	if err != nil {
+		t.Fatalf("VerifyScheduleWithSettings failed: %v", err)
+	}
+	if !report.Valid {
+		t.Errorf("expected exams exactly the minimum gap apart to be valid, got %+v", report)
+	}
+
+	report, _ = VerifyScheduleWithSettings(regs, scheduleCSV, nil, slots, nil, 180, nil)
+	if report.Valid || len(report.ConstraintViolations) != 1 {
+		t.Errorf("expected a gap of 120 minutes to fail a minimum gap of 180, got %+v", report)
+	}
+}
@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+// ExamWindow describes the span of the timetable actually used by a schedule.
+type ExamWindow struct {
+	Days       int       `json:"days"`       // Number of exam days from the first slot up to the last used day
+	DaysUsed   int       `json:"daysUsed"`   // Number of distinct days that hold at least one exam
+	SlotsUsed  int       `json:"slotsUsed"`  // Number of distinct slots that hold at least one exam
+	LastSlotID SlotID    `json:"lastSlotId"` // Latest slot that holds an exam
+	Start      time.Time `json:"start"`      // Start of the first available slot
+	End        time.Time `json:"end"`        // End of the latest used slot
+}
+
+// ComputeExamWindow reports the window used by the given assignments.
+// It returns nil if no assignment refers to a known slot.
+func ComputeExamWindow(assignments []*Assignment, slots []*Slot) *ExamWindow {
+	slotByID := make(map[SlotID]*Slot, len(slots))
+	for _, s := range slots {
+		slotByID[s.ID] = s
+	}
+
+	var last *Slot
+	usedSlots := make(map[SlotID]bool)
+	usedDays := make(map[int]bool)
+	for _, a := range assignments {
+		slot, ok := slotByID[a.SlotID]
+		if !ok {
+			continue
+		}
+		usedSlots[slot.ID] = true
+		usedDays[slot.DayIndex] = true
+		if last == nil || slot.End.After(last.End) {
+			last = slot
+		}
+	}
+	if last == nil {
+		return nil
+	}
+
+	return &ExamWindow{
+		Days:       last.DayIndex + 1,
+		DaysUsed:   len(usedDays),
+		SlotsUsed:  len(usedSlots),
+		LastSlotID: last.ID,
+		Start:      slots[0].Start,
+		End:        last.End,
+	}
+}
+
+// slotsWithinDays returns the prefix of slots that fall on the first n exam days.
+// Slots are expected in chronological order, as produced by GenerateSlots.
+func slotsWithinDays(slots []*Slot, n int) []*Slot {
+	for i, s := range slots {
+		if s.DayIndex >= n {
+			return slots[:i]
+		}
+	}
+	return slots
+}
+
+// MinimizeExamDays searches for the schedule that finishes on the earliest exam day.
+// It binary-searches over the number of leading exam days, running RunSchedulingAttempts
+// on each truncated slot list, and returns the best result found for the smallest
+// feasible window. The achieved window is reported in ScheduleResult.Window.
+func MinimizeExamDays(
+	tries int,
+	seed int64,
+	courses map[CourseID]*Course,
+	halls []*Hall,
+	slots []*Slot,
+	allowedSlots map[CourseID]map[SlotID]bool,
+	graph *ConflictGraph,
+	minGapMinutes int,
+	penaltyConfig PenaltyConfig,
+) (*ScheduleResult, error) {
+	return MinimizeExamDaysWithConstraints(tries, seed, courses, halls, slots, allowedSlots, graph, minGapMinutes, penaltyConfig, nil)
+}
+
+// MinimizeExamDaysWithConstraints runs MinimizeExamDays with the given constraints, which may be nil.
+func MinimizeExamDaysWithConstraints(
+	tries int,
+	seed int64,
+	courses map[CourseID]*Course,
+	halls []*Hall,
+	slots []*Slot,
+	allowedSlots map[CourseID]map[SlotID]bool,
+	graph *ConflictGraph,
+	minGapMinutes int,
+	penaltyConfig PenaltyConfig,
+	constraints *Constraints,
+) (*ScheduleResult, error) {
+	if len(slots) == 0 {
+		return nil, fmt.Errorf("no slots available")
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	// The full window is the upper bound; if it fails there is nothing to compress.
+	best, err := RunSchedulingAttemptsWithConstraints(tries, seed, courses, halls, slots, allowedSlots, graph, minGapMinutes, penaltyConfig, constraints)
+	if err != nil {
+		return nil, err
+	}
+	if best.Window == nil {
+		return best, nil
+	}
+
+	lo, hi := 1, best.Window.Days
+	for lo < hi {
+		mid := (lo + hi) / 2
+		result, err := RunSchedulingAttemptsWithConstraints(tries, seed, courses, halls, slotsWithinDays(slots, mid), allowedSlots, graph, minGapMinutes, penaltyConfig, constraints)
+		if err != nil {
+			lo = mid + 1
+			continue
+		}
+		best = result
+		// The result may finish earlier than the window it was given.
+		hi = result.Window.Days
+	}
+
+	return best, nil
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"testing"
+)
+
+func TestComputeExamWindow(t *testing.T) {
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-08", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	assignments := []*Assignment{
+		{CourseID: "c1", SlotID: slots[0].ID},
+		{CourseID: "c2", SlotID: slots[0].ID},
+		{CourseID: "c3", SlotID: slots[3].ID},
+	}
+
+	window := ComputeExamWindow(assignments, slots)
+	if window == nil {
+		t.Fatal("expected a window, got nil")
+	}
+	if window.Days != 2 {
+		t.Errorf("expected window of 2 days, got %d", window.Days)
+	}
+	if window.DaysUsed != 2 {
+		t.Errorf("expected 2 days used, got %d", window.DaysUsed)
+	}
+	if window.SlotsUsed != 2 {
+		t.Errorf("expected 2 slots used, got %d", window.SlotsUsed)
+	}
+	if window.LastSlotID != slots[3].ID {
+		t.Errorf("expected last slot %s, got %s", slots[3].ID, window.LastSlotID)
+	}
+}
+
+func TestMinimizeExamDays(t *testing.T) {
+	// c1-c2-c3 form a clique, c4 is isolated: 3 slots are needed, i.e. 2 days with 2 slots per day.
+	courses := map[CourseID]*Course{
+		"c1": {ID: "c1", Enrollments: []StudentID{"s1", "s2"}},
+		"c2": {ID: "c2", Enrollments: []StudentID{"s1", "s3"}},
+		"c3": {ID: "c3", Enrollments: []StudentID{"s2", "s3"}},
+		"c4": {ID: "c4", Enrollments: []StudentID{"s4"}},
+	}
+	halls := []*Hall{{ID: "H1", Capacity: 10}, {ID: "H2", Capacity: 10}}
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-10", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	graph := NewConflictGraph(courses)
+
+	result, err := MinimizeExamDays(10, 42, courses, halls, slots, make(map[CourseID]map[SlotID]bool), graph, 0, PenaltyConfig{StudentProximityWeight: 1.0})
+	if err != nil {
+		t.Fatalf("MinimizeExamDays failed: %v", err)
+	}
+	if result.Window == nil || result.Window.Days != 2 {
+		t.Fatalf("expected a 2-day window, got %+v", result.Window)
+	}
+	if len(result.Assignments) != 4 {
+		t.Errorf("expected 4 assignments, got %d", len(result.Assignments))
+	}
+}
+
+func TestMinimizeExamDays_RespectsAllowedSlots(t *testing.T) {
+	courses := map[CourseID]*Course{
+		"c1": {ID: "c1", Enrollments: []StudentID{"s1"}},
+	}
+	halls := []*Hall{{ID: "H1", Capacity: 10}}
+	slots, _ := GenerateSlots("2025-01-06", "2025-01-10", 2, []string{"09:00", "14:00"}, 180, nil, "UTC")
+	graph := NewConflictGraph(courses)
+	allowed := map[CourseID]map[SlotID]bool{"c1": {slots[6].ID: true}}
+
+	result, err := MinimizeExamDays(5, 42, courses, halls, slots, allowed, graph, 0, PenaltyConfig{})
+	if err != nil {
+		t.Fatalf("MinimizeExamDays failed: %v", err)
+	}
+	if result.Window.Days != 4 {
+		t.Errorf("expected a 4-day window, got %d", result.Window.Days)
+	}
+}
@@ -16,12 +16,26 @@ export interface ColumnMapping {
   studentIDColumn?: string;
   /** Column name for course ID (default: "course_id") */
   courseIDColumn?: string;
+  /**
+   * Course columns of a wide registrations file with one row per student, e.g.
+   * ["course1", ..., "course8"]; empty cells are skipped. Replaces courseIDColumn.
+   */
+  courseColumns?: string[];
+  /**
+   * Delimiter for course cells listing several courses, e.g. ";" with courseIDColumn "courses"
+   * for rows like s001,"CS101;MATH201". Setting it also makes the file wide.
+   */
+  courseDelimiter?: string;
   /** Column name for hall ID (default: "hall") */
   hallIDColumn?: string;
   /** Column name for hall capacity (default: "capacity") */
   capacityColumn?: string;
-  /** Column name for hall group (default: "group") */
+  /** Column name for hall group, used as the hall's campus (default: "group") */
   groupColumn?: string;
+  /** Column flagging designated rooms for accommodated students (default: "accommodation") */
+  accommodationColumn?: string;
+  /** Column listing semicolon-separated hall features, e.g. "lab;accessible" (default: "features") */
+  featuresColumn?: string;
 }
 
 export interface RunScheduleParams {
@@ -43,6 +57,14 @@ export interface RunScheduleParams {
   /** Array of ISO date strings to skip (holidays) */
   holidays: string[];
 
+  /**
+   * Optional CSV text listing the slots explicitly, replacing the generated ones:
+   * id,start,end,day
+   * (start/end as "YYYY-MM-DD HH:MM" in the timezone or RFC 3339; id and day are optional,
+   * slots with the same day label count as one day; overlapping slots are rejected)
+   */
+  slotsCSV?: string;
+
   /** Number of scheduling attempts to try (default: 100) */
   tries: number;
 
@@ -52,14 +74,127 @@ export interface RunScheduleParams {
   /** Minimum gap between exams for a student in minutes (optional) */
   minGap?: number;
 
-  /** Optional CSV text for per-course allowed slots restriction */
+  /**
+   * Optional CSV text for per-course allowed slots restriction (course_id,slot_id).
+   * slot_id may be an exact slot ID or a pattern such as "2025-05-12..2025-05-16 morning",
+   * "Fri #2" or "14:00"; several rows for a course are combined.
+   */
   allowedSlotsCSV?: string;
 
-  /** IANA timezone string (optional, default: "UTC") */
+  /** Fail when any row of the registrations, halls, slots or constraint inputs has to be skipped (optional, default: false) */
+  strictInput?: boolean;
+
+  /**
+   * IANA timezone string (optional, default: "UTC"). Slot times are local wall-clock times and
+   * slot IDs carry the UTC offset, e.g. "2025-03-31T09:00+02:00#1" ("Z" for UTC).
+   * An unknown timezone is an error.
+   */
   timezone?: string;
 
   /** Custom column mapping for CSV parsing */
   columnMapping?: ColumnMapping;
+
+  /**
+   * Format of the registrations input (optional, default: "csv"). With "xlsx" or "ods" the
+   * registrations argument is the workbook (a Uint8Array or a base64 string), read with the same
+   * column mapping; diagnostics then give sheet row numbers.
+   */
+  registrationsFormat?: "csv" | "xlsx" | "ods";
+
+  /** Sheet of the registrations workbook to read (optional, default: the first sheet; case-insensitive) */
+  registrationsSheet?: string;
+
+  /** Format of the halls input, as registrationsFormat (optional, default: "csv") */
+  hallsFormat?: "csv" | "xlsx" | "ods";
+
+  /** Sheet of the halls workbook to read (optional, default: the first sheet) */
+  hallsSheet?: string;
+
+  /**
+   * Optimisation objective (optional, default: "penalty").
+   * "minDays" compresses the timetable into the fewest exam days.
+   */
+  objective?: "penalty" | "minDays";
+
+  /**
+   * Optional CSV text with student constraints:
+   * student_id,unavailable,extra_time,separate_room
+   * (unavailable is a semicolon-separated list of slot patterns, see forbiddenSlotsCSV)
+   */
+  studentConstraintsCSV?: string;
+
+  /**
+   * Optional CSV text with course relations: course_a,relation,course_b,min_gap
+   * (relation is same_slot, different_slot, same_day, not_same_day, precedes or follows;
+   * min_gap in minutes applies to precedes/follows)
+   */
+  courseRelationsCSV?: string;
+
+  /**
+   * Optional CSV text with forbidden slots: course_id,department,slot
+   * (one of course_id/department per row; slot is a slot ID or a space-separated combination
+   * of a date, weekday, morning/afternoon/evening or HH:MM start time, e.g. "Fri afternoon";
+   * a slot that matches none of the exam slots is reported as a warning diagnostic)
+   */
+  forbiddenSlotsCSV?: string;
+
+  /**
+   * Optional CSV text of hall features each course needs:
+   * course_id,features
+   * (features is semicolon-separated and matched against the halls' features column)
+   */
+  courseRequirementsCSV?: string;
+
+  /**
+   * Optional CSV text of hall availability windows:
+   * hall,slot,available
+   * (slot is a slot pattern as in forbiddenSlotsCSV; available is available/unavailable or yes/no,
+   * default unavailable; halls are available by default and the last matching row wins; a slot that
+   * matches none of the exam slots is reported as a warning diagnostic)
+   */
+  hallAvailabilityCSV?: string;
+
+  /**
+   * Optional CSV text of travel times between campuses, where a hall's campus is its group column:
+   * from,to,minutes
+   * (symmetric; students with consecutive same-day exams at campuses further apart than the
+   * gap between the exams are penalised and reported in travelWarnings)
+   */
+  travelTimesCSV?: string;
+
+  /** Penalty per student who cannot reach the next exam's campus in time (optional, default: 10; 0 turns it off) */
+  campusTravelWeight?: number;
+
+  /** Penalty weight of a student's exams being close together (optional, default: 1; 0 turns it off) */
+  studentProximityWeight?: number;
+
+  /** Penalty weight of a student's exams being closer than minGap (optional, default: 10; 0 turns it off) */
+  minGapViolationWeight?: number;
+
+  /**
+   * Optional CSV text of course codes that share one exam (cross-listings or sections):
+   * course_id,exam_id
+   * (courses with the same exam_id are scheduled and seated as one exam; the schedule CSV still
+   * has a row for every course code)
+   */
+  courseAliasesCSV?: string;
+
+  /**
+   * Optional CSV text of courses to split into several sittings:
+   * course_id,sittings
+   * (each sitting is scheduled as its own exam, named e.g. "INTRO101/S1", in a different slot
+   * from the other sittings; the student assignment is returned as sittingsCSV)
+   */
+  courseSittingsCSV?: string;
+
+  /**
+   * Optional sittingsCSV returned with a published schedule (verify, repairSchedule and
+   * exportScheduleHTML only), so its sittings are checked and kept rather than treated as courses
+   */
+  sittingsCSV?: string;
+
+  /** Heading of the HTML report (exportScheduleHTML only, default: "Exam timetable") */
+  reportTitle?: string;
 }
 
 // ===== OUTPUT TYPES =====
@@ -82,6 +217,13 @@ export interface ValidationReport {
 
   /** Array of student clash descriptions */
   studentClashes?: string[];
+
+  /** Broken hard constraints beyond student clashes, including exams closer than minGap when verify is given params */
+  constraintViolations?: string[] | null;
+
+  /** Students who cannot reach the campus of their next exam in time (soft, does not affect valid) */
+  travelWarnings?: string[] | null;
+
 }
 
 export interface ScheduleStats {
@@ -99,6 +241,204 @@ export interface ScheduleStats {
 
   /** Number of time slots actually used */
   slotsUsed: number;
+
+  /** Number of distinct days that hold at least one exam */
+  daysUsed: number;
+
+  /** Exam days from the start date up to the last used day */
+  windowDays: number;
+
+  /** RFC3339 end time of the last exam */
+  windowEnd?: string;
+}
+
+export interface CourseChange {
+  /** Course whose placement changed */
+  courseId: string;
+
+  /** Kind of change */
+  kind: "added" | "removed" | "slotChanged" | "hallChanged";
+
+  /** Previous slot ID (absent for newly added courses) */
+  fromSlot?: string;
+
+  /** New slot ID (absent for dropped courses) */
+  toSlot?: string;
+
+  /** Previous semicolon-separated halls */
+  fromHalls?: string;
+
+  /** New semicolon-separated halls */
+  toHalls?: string;
+
+  /** Students enrolled in the changed course */
+  affectedStudents: string[];
+}
+
+export interface ScheduleDiff {
+  /** Every course that changed, sorted by course ID */
+  changes: CourseChange[];
+
+  /** Union of students affected by any change */
+  affectedStudents: string[];
+}
+
+export interface DiffResponse {
+  success: true;
+
+  /** Structured diff */
+  diff: ScheduleDiff;
+
+  /** Human-readable summary, one line per change */
+  text: string;
+}
+
+/**
+ * A saved scheduling session: inputs as CSV text plus every setting needed to re-run it.
+ * Produced by saveScenario and run by runScenario; the version is checked on load.
+ */
+export interface Scenario {
+  version: number;
+  name?: string;
+  registrationsCSV: string;
+  hallsCSV: string;
+  allowedSlotsCSV?: string;
+  columnMapping?: ColumnMapping;
+  strictInput?: boolean;
+  slots: {
+    startDate?: string;
+    endDate?: string;
+    slotsPerDay?: number;
+    slotTimes?: string[];
+    slotDuration?: number;
+    holidays?: string[];
+    timezone?: string;
+    slotsCSV?: string;
+  };
+  constraints: {
+    studentConstraintsCSV?: string;
+    courseRelationsCSV?: string;
+    forbiddenSlotsCSV?: string;
+    courseRequirementsCSV?: string;
+    hallAvailabilityCSV?: string;
+    travelTimesCSV?: string;
+    courseAliasesCSV?: string;
+    courseSittingsCSV?: string;
+  };
+  /** Weights as given in the params; a missing weight takes its default */
+  penalty: {
+    studentProximityWeight?: number;
+    minGapViolationWeight?: number;
+    campusTravelWeight?: number;
+  };
+  /** Seed of the run, never 0 */
+  seed: number;
+  tries: number;
+  minGap: number;
+  objective?: "penalty" | "minDays";
+}
+
+export interface ScenarioResponse {
+  success: true;
+
+  /** Scenario JSON, see Scenario */
+  scenario: string;
+}
+
+export interface PenaltyTerm {
+  /** How often the penalty occurs */
+  count: number;
+  weight: number;
+
+  /** count * weight */
+  penalty: number;
+}
+
+export interface PenaltyBreakdown {
+  /** Pairs of a student's exams on the same day */
+  studentProximity: PenaltyTerm;
+
+  /** Pairs of a student's exams closer than the minimum gap */
+  minGapViolation: PenaltyTerm;
+
+  /** Students who cannot reach the campus of their next exam */
+  campusTravel: PenaltyTerm;
+}
+
+export interface ExportSlot {
+  id: string;
+
+  /** RFC3339 start and end times */
+  start: string;
+  end: string;
+  dayIndex: number;
+  indexInDay: number;
+}
+
+export interface ExportHall {
+  id: string;
+  capacity: number;
+  group?: string;
+  accommodation?: boolean;
+  features?: string[];
+}
+
+export interface ExportExam {
+  courseId: string;
+  slotId: string;
+  start: string;
+  end: string;
+  dayIndex: number;
+  halls: ExportHall[];
+  enrolled: number;
+
+  /** Capacity of the exam's halls */
+  seats: number;
+
+  /** Enrolled students beyond the seats */
+  unseated?: number;
+  notes?: string;
+}
+
+/** Parsed form of SuccessResponse.scheduleJSON */
+export interface ScheduleExport {
+  version: number;
+  seed: number;
+
+  /** Checksum of each input ("sha256:<hex>"), keyed by "registrations", "halls" and "allowedSlots" */
+  inputs?: Record<string, string>;
+  penalty: number;
+  penaltyBreakdown?: PenaltyBreakdown;
+  window?: {
+    days: number;
+    daysUsed: number;
+    slotsUsed: number;
+    lastSlotId: string;
+    start: string;
+    end: string;
+  };
+  slots: ExportSlot[];
+  halls: ExportHall[];
+  exams: ExportExam[];
+  unassigned?: string[];
+}
+
+export interface ReportResponse {
+  success: true;
+
+  /**
+   * Self-contained HTML document: a day-by-slot grid, hall occupancy, a listing per department
+   * (the leading letters of the course codes) and the validation summary. It has print styles,
+   * so the browser's print dialog can save it as PDF.
+   */
+  html: string;
+}
+
+export interface ExportResponse {
+  success: true;
+
+  /** Base64-encoded XLSX workbook: one sheet per exam day ("2025-01-06 Mon") and a "Halls" sheet */
+  xlsx: string;
 }
 
 export interface SuccessResponse {
@@ -107,11 +447,46 @@ export interface SuccessResponse {
   /** CSV string with headers: course_id,slot_id,slot_datetime,halls,enrolled_count,notes */
   scheduleCSV: string;
 
+  /** The schedule as JSON with its slots, halls, seats, penalty breakdown, seed and input checksums; see ScheduleExport (runSchedule and runScenario only) */
+  scheduleJSON?: string;
+
   /** Validation report for the generated schedule */
   report: ValidationReport;
 
   /** Statistics about the scheduling process */
   stats: ScheduleStats;
+
+  /** Courses that moved (repairSchedule only) */
+  changes?: CourseChange[];
+
+  /** CSV string with headers student_id,course_id,sitting_id; only set when courses were split */
+  sittingsCSV?: string;
+
+  /**
+   * Rows of the registrations, halls and allowed slots CSVs that were skipped or look suspicious,
+   * including repeated registrations, IDs differing only in case or spacing, and allowed slots
+   * for unknown courses
+   */
+  diagnostics?: InputDiagnostic[];
+}
+
+export interface InputDiagnostic {
+  /**
+   * Input the row belongs to: "registrations", "halls", "allowed slots", "slots", "staff" or a
+   * constraint input such as "course relations" or "forbidden slots"
+   */
+  file: string;
+
+  /** 1-based line number in the file */
+  line: number;
+
+  /** Column name, if the problem is in one field */
+  column?: string;
+
+  /** "error" rows were skipped; "warning" rows look wrong or repeat another row but the data is usable */
+  severity: "error" | "warning";
+
+  message: string;
 }
 
 export interface ErrorResponse {
@@ -125,10 +500,58 @@ export interface ErrorResponse {
 
   /** Partial statistics if available */
   stats?: ScheduleStats;
+
+  /** Input rows that were skipped; with strictInput these are why the run failed */
+  diagnostics?: InputDiagnostic[];
 }
 
 export type ScheduleResponse = SuccessResponse | ErrorResponse;
 
+export interface InvigilationParams {
+  /** One invigilator per this many students in a hall (default: 30) */
+  studentsPerInvigilator?: number;
+
+  /** Staff may not invigilate their own courses (or their department's, if no courses are listed) */
+  noOwnCourse?: boolean;
+
+  /** Custom column mapping for the halls CSV */
+  columnMapping?: ColumnMapping;
+
+  /** Optional sittingsCSV of the schedule, so staff count as teaching each sitting of their courses */
+  sittingsCSV?: string;
+}
+
+export interface Duty {
+  staffId: string;
+  slotId: string;
+  hallId: string;
+  courses: string[];
+  students: number;
+}
+
+export interface RosterReport {
+  /** Whether every hall is fully staffed and no rule is broken */
+  valid: boolean;
+  understaffed: string[] | null;
+  overloaded: string[] | null;
+  errors: string[] | null;
+
+  /** Number of duties per staff member */
+  dutyCounts: Record<string, number>;
+}
+
+export interface RosterResponse {
+  success: true;
+
+  /** CSV string with headers: staff_id,slot_id,hall,courses,students */
+  rosterCSV: string;
+  roster: Duty[] | null;
+  report: RosterReport;
+
+  /** Halls and staff rows that were skipped */
+  diagnostics?: InputDiagnostic[];
+}
+
 export interface VersionInfo {
   /** Name of the scheduler module */
   name: string;
@@ -155,20 +578,103 @@ export interface WasmAPI {
 
   /**
    * Run the exam scheduling algorithm
-   * @param regCSV - CSV string with registrations (student_id,course_id header required)
-   * @param hallsCSV - CSV string with halls (hall,capacity,group header required)
+   * @param regCSV - CSV with registrations (student_id,course_id header required), or a workbook
+   *   if params.registrationsFormat is "xlsx" or "ods". Pass large files as a Uint8Array: it is
+   *   read in chunks instead of being copied into the module whole. Workbooks passed as a
+   *   string must be base64-encoded.
+   * @param hallsCSV - CSV with halls (hall,capacity,group header required), or a workbook if
+   *   params.hallsFormat is "xlsx" or "ods"; a string or a Uint8Array as for regCSV
    * @param paramsJSON - JSON string of RunScheduleParams
    * @returns JSON string containing ScheduleResponse
    */
-  runSchedule(regCSV: string, hallsCSV: string, paramsJSON: string): string;
+  runSchedule(regCSV: string | Uint8Array, hallsCSV: string | Uint8Array, paramsJSON: string): string;
 
   /**
    * Verify an existing schedule for correctness
    * @param regCSV - CSV string with registrations
-   * @param scheduleCSV - CSV string with schedule to verify
-   * @returns JSON string containing ValidationReport wrapped in success response
+   * @param scheduleCSV - CSV string with schedule to verify, or a scheduleJSON, which is checked against its own halls and slots
+   * @param hallsCSV - Optional halls; without them, hall capacities are not checked
+   * @param paramsJSON - Optional JSON string of RunScheduleParams; with slot settings, every exam
+   *   must be in a known slot at its time and in its allowed slots, and the minimum gap is checked.
+   *   The constraint inputs are checked too. A scheduleJSON is always checked against its own slots,
+   *   with the allowed slots, minimum gap and constraints of the params.
+   * @returns JSON string containing ValidationReport wrapped in success response, or ErrorResponse
+   */
+  verify(regCSV: string | Uint8Array, scheduleCSV: string, hallsCSV?: string | Uint8Array, paramsJSON?: string): string;
+
+  /**
+   * Repair a published schedule after registration changes, moving as few exams as possible
+   * @param publishedCSV - CSV with the published schedule, as a string or a Uint8Array
+   * @param regCSV - Updated registrations, as for runSchedule
+   * @param hallsCSV - Halls, as for runSchedule
+   * @param paramsJSON - JSON string of RunScheduleParams (slot settings must match the published run)
+   * @returns JSON string containing ScheduleResponse with the list of changes
+   */
+  repairSchedule(
+    publishedCSV: string | Uint8Array,
+    regCSV: string | Uint8Array,
+    hallsCSV: string | Uint8Array,
+    paramsJSON: string,
+  ): string;
+
+  /**
+   * Compare two schedules
+   * @param beforeCSV - CSV string with the older schedule
+   * @param afterCSV - CSV string with the newer schedule
+   * @param regCSV - Optional CSV string with registrations, used to list affected students
+   * @param paramsJSON - Optional JSON string of RunScheduleParams (only columnMapping is used)
+   * @param previousRegCSV - Optional CSV string with the registrations of the older schedule; removed
+   *   courses then list their previous students and moved courses both their previous and current ones
+   * @returns JSON string containing DiffResponse or ErrorResponse
+   */
+  diffSchedules(beforeCSV: string, afterCSV: string, regCSV?: string, paramsJSON?: string, previousRegCSV?: string): string;
+
+  /**
+   * Build an invigilator duty roster for a schedule
+   * @param scheduleCSV - CSV string with the schedule
+   * @param hallsCSV - CSV string with halls
+   * @param staffCSV - CSV string with staff (staff_id,department,max_duties,unavailable,courses;
+   *   unavailable is a semicolon-separated list of slot patterns, see forbiddenSlotsCSV)
+   * @param paramsJSON - Optional JSON string of InvigilationParams
+   * @returns JSON string containing RosterResponse or ErrorResponse
+   */
+  assignInvigilators(scheduleCSV: string, hallsCSV: string, staffCSV: string, paramsJSON?: string): string;
+
+  /**
+   * Export a schedule as an Excel workbook
+   * @param scheduleCSV - CSV string with the schedule
+   * @param paramsJSON - JSON string of RunScheduleParams (the slot settings give each exam its day)
+   * @returns JSON string containing ExportResponse or ErrorResponse
+   */
+  exportScheduleXLSX(scheduleCSV: string, paramsJSON: string): string;
+
+  /**
+   * Render a printable HTML report of a schedule
+   * @param regCSV - Registrations for the validation summary; pass "" to leave it out
+   * @param scheduleCSV - CSV string with the schedule
+   * @param hallsCSV - Halls for the occupancy view
+   * @param paramsJSON - JSON string of RunScheduleParams (slot settings, constraints and reportTitle)
+   * @returns JSON string containing ReportResponse or ErrorResponse
+   */
+  exportScheduleHTML(regCSV: string | Uint8Array, scheduleCSV: string, hallsCSV: string | Uint8Array, paramsJSON: string): string;
+
+  /**
+   * Bundle the inputs and params of a run into a scenario for archiving. Workbook inputs are
+   * stored as CSV. params.seed must be set to the stats.seed of the run, so it can be repeated
+   * exactly; without it saveScenario fails.
+   * @param regCSV - Registrations, as for runSchedule
+   * @param hallsCSV - Halls, as for runSchedule
+   * @param paramsJSON - JSON string of RunScheduleParams
+   * @returns JSON string containing ScenarioResponse or ErrorResponse
+   */
+  saveScenario(regCSV: string | Uint8Array, hallsCSV: string | Uint8Array, paramsJSON: string): string;
+
+  /**
+   * Run a saved scenario with its own inputs, settings and seed
+   * @param scenarioJSON - Scenario JSON as returned by saveScenario
+   * @returns JSON string containing ScheduleResponse
    */
-  verify(regCSV: string, scheduleCSV: string): string;
+  runScenario(scenarioJSON: string): string;
 }
 
 // ===== USAGE DOCUMENTATION =====
@@ -245,6 +751,8 @@ export interface WasmAPI {
  *    Registrations CSV:
  *    - Default headers: student_id, course_id
  *    - Can be customized via columnMapping parameter
+ *    - Wide files with one row per student are read with columnMapping.courseColumns
+ *      (course1..course8) or columnMapping.courseDelimiter (a "CS101;MATH201" courses column)
  *    - Additional columns are ignored
  *    - Handles quoted fields with commas: "Doe, Jane",CS101
  *    - Comment lines starting with # are ignored
@@ -73,8 +73,8 @@ self.onmessage = async (event: MessageEvent) => {
                 break;
             }
             case 'VERIFY_SCHEDULE': {
-                const { regCSV, scheduleCSV } = data;
-                const reportJson = globalThis.verify(regCSV, scheduleCSV);
+                const { regCSV, scheduleCSV, hallsCSV, paramsJSON } = data;
+                const reportJson = globalThis.verify(regCSV, scheduleCSV, hallsCSV, paramsJSON);
                 const report = JSON.parse(reportJson);
                 postMessage({ type: 'VERIFY_RESULT', data: report });
                 break;